@@ -2,8 +2,11 @@ package file
 
 import (
 	"github.com/coder2z/g-saber/xfile"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/fsnotify/fsnotify"
 	"io/ioutil"
 	"path/filepath"
+	"sync"
 )
 
 // fileDataSource file provider.
@@ -12,6 +15,8 @@ type fileDataSource struct {
 	dir         string
 	enableWatch bool
 	changed     chan struct{}
+	done        chan struct{}
+	closeOnce   sync.Once
 }
 
 // NewDataSource returns new fileDataSource.
@@ -24,7 +29,8 @@ func NewDataSource(path string, watch bool) *fileDataSource {
 	ds := &fileDataSource{path: absolutePath, dir: dir, enableWatch: watch}
 	if watch {
 		ds.changed = make(chan struct{}, 1)
-		go ds.watch()
+		ds.done = make(chan struct{})
+		ds.watch()
 	}
 	return ds
 }
@@ -34,9 +40,13 @@ func (fp *fileDataSource) ReadConfig() (content []byte, err error) {
 	return ioutil.ReadFile(fp.path)
 }
 
-// Close ...
+// Close 停止监听，监听协程退出后关闭 changed
 func (fp *fileDataSource) Close() error {
-	close(fp.changed)
+	if fp.enableWatch {
+		fp.closeOnce.Do(func() {
+			close(fp.done)
+		})
+	}
 	return nil
 }
 
@@ -46,4 +56,57 @@ func (fp *fileDataSource) IsConfigChanged() <-chan struct{} {
 }
 
 // Watch file and automate update.
-func (fp *fileDataSource) watch() {}
+// 监听文件所在目录，编辑器或 k8s ConfigMap 替换文件时也能收到通知；
+// 返回前完成监听注册，之后的修改不会丢失
+func (fp *fileDataSource) watch() {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		fp.error("New file watcher error", err)
+		close(fp.changed)
+		return
+	}
+	if err := w.Add(fp.dir); err != nil {
+		fp.error("Watch config dir error", err)
+		_ = w.Close()
+		close(fp.changed)
+		return
+	}
+	go fp.run(w)
+}
+
+func (fp *fileDataSource) run(w *fsnotify.Watcher) {
+	defer close(fp.changed)
+	defer w.Close()
+	for {
+		select {
+		case <-fp.done:
+			return
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 || filepath.Clean(event.Name) != fp.path {
+				continue
+			}
+			select {
+			case fp.changed <- struct{}{}:
+			default:
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			fp.error("Read watch event error", err)
+		}
+	}
+}
+
+func (fp *fileDataSource) error(desc string, err error) {
+	xlog.Error("Application Config",
+		xlog.FieldComponentName("XDataSource"),
+		xlog.FieldMethod("XDataSource.File.Watch"),
+		xlog.FieldDescription(desc),
+		xlog.FieldName(fp.path),
+		xlog.FieldErr(err),
+	)
+}
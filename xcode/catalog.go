@@ -0,0 +1,210 @@
+package xcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/coder2z/g-saber/xjson"
+	"google.golang.org/grpc/codes"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CatalogVersion 错误码目录的格式版本，格式不兼容变更时递增
+const CatalogVersion = "v1"
+
+var (
+	_codeNames sync.Map
+	conflictMu sync.Mutex
+	conflicts  []error
+)
+
+type codeKey struct {
+	codeT uint
+	code  uint32
+}
+
+func setName(codeT uint, code uint32, name string) {
+	if actual, loaded := _codeNames.LoadOrStore(codeKey{codeT, code}, name); loaded && actual.(string) != name {
+		addConflict(fmt.Errorf("%s code %d named %q, conflicts with %q", typeName(codeT), code, name, actual))
+	}
+}
+
+func addConflict(err error) {
+	conflictMu.Lock()
+	defer conflictMu.Unlock()
+	conflicts = append(conflicts, err)
+}
+
+// resetConflicts 清空已记录的冲突
+func resetConflicts() {
+	conflictMu.Lock()
+	defer conflictMu.Unlock()
+	conflicts = nil
+}
+
+// Conflicts 返回注册过程中发现的重复或冲突的错误码，应用启动时检查，存在冲突时拒绝启动
+func Conflicts() error {
+	conflictMu.Lock()
+	defer conflictMu.Unlock()
+	if len(conflicts) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(conflicts))
+	for _, err := range conflicts {
+		msgs = append(msgs, err.Error())
+	}
+	return errors.New("xcode conflicts: " + strings.Join(msgs, "; "))
+}
+
+func typeName(codeT uint) string {
+	if codeT == BusinessType {
+		return "business"
+	}
+	return "system"
+}
+
+// CatalogEntry 目录中的一个错误码
+type CatalogEntry struct {
+	Type    string `json:"type"`
+	Code    uint32 `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// Catalog 已注册错误码的目录，Checksum 由所有错误码计算，客户端可以据此判断目录是否变化
+type Catalog struct {
+	Version  string         `json:"version"`
+	Checksum string         `json:"checksum"`
+	Codes    []CatalogEntry `json:"codes"`
+}
+
+// ExportCatalog 按类型和错误码排序导出当前注册的错误码
+func ExportCatalog() *Catalog {
+	entries := make([]CatalogEntry, 0)
+	for _, codeT := range []uint{SystemType, BusinessType} {
+		registry(codeT).Range(func(key, val interface{}) bool {
+			code := key.(uint32)
+			entries = append(entries, CatalogEntry{
+				Type:    typeName(codeT),
+				Code:    code,
+				Name:    codeName(codeT, code),
+				Message: val.(*spbStatus).Message,
+			})
+			return true
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type > entries[j].Type
+		}
+		return entries[i].Code < entries[j].Code
+	})
+	uniqueNames(entries)
+
+	h := sha256.New()
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s\n", e.Type, e.Code, e.Name, e.Message)
+	}
+	return &Catalog{
+		Version:  CatalogVersion,
+		Checksum: hex.EncodeToString(h.Sum(nil)),
+		Codes:    entries,
+	}
+}
+
+// codeName 未指定名称时，系统错误码使用 gRPC 错误码名称，其他使用 类型_错误码
+func codeName(codeT uint, code uint32) string {
+	if name, ok := _codeNames.Load(codeKey{codeT, code}); ok {
+		return name.(string)
+	}
+	if codeT == SystemType {
+		if c := codes.Code(code % 10000); c <= codes.Unauthenticated {
+			name := upperSnake(c.String())
+			if code >= 10000 {
+				name = fmt.Sprintf("%s_%d", name, code/10000)
+			}
+			return name
+		}
+	}
+	return fmt.Sprintf("%s_%d", strings.ToUpper(typeName(codeT)), code)
+}
+
+// uniqueNames proto 枚举值在包内必须唯一，重名时追加错误码
+func uniqueNames(entries []CatalogEntry) {
+	seen := make(map[string]bool, len(entries))
+	for i := range entries {
+		if seen[entries[i].Name] {
+			entries[i].Name = fmt.Sprintf("%s_%d", entries[i].Name, entries[i].Code)
+		}
+		seen[entries[i].Name] = true
+	}
+}
+
+func upperSnake(s string) string {
+	var buf bytes.Buffer
+	var prev rune
+	for _, r := range s {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			buf.WriteByte('_')
+		}
+		buf.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	return buf.String()
+}
+
+// WriteJSON 以 JSON 输出目录
+func (c *Catalog) WriteJSON(w io.Writer) error {
+	return xjson.NewEncoder(w).Encode(c)
+}
+
+// WriteProto 以 proto3 枚举输出目录，pkg 为 proto 包名
+func (c *Catalog) WriteProto(w io.Writer, pkg string) error {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by xcode. DO NOT EDIT.\n")
+	fmt.Fprintf(&buf, "// catalog version: %s, checksum: %s\n\n", c.Version, c.Checksum)
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&buf, "package %s;\n\n", pkg)
+	buf.WriteString("enum Code {\n")
+	// 带应用前缀的系统错误码可能与业务错误码数值相同
+	values := make(map[uint32]bool, len(c.Codes))
+	for _, e := range c.Codes {
+		if values[e.Code] {
+			buf.WriteString("  option allow_alias = true;\n")
+			break
+		}
+		values[e.Code] = true
+	}
+	// proto3 枚举的第一个值必须为 0
+	if len(c.Codes) == 0 || c.Codes[0].Code != 0 || c.Codes[0].Type != typeName(SystemType) {
+		buf.WriteString("  CODE_UNSPECIFIED = 0;\n")
+	}
+	for _, e := range c.Codes {
+		fmt.Fprintf(&buf, "  %s = %d; // %s\n", e.Name, e.Code, strings.ReplaceAll(e.Message, "\n", " "))
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// XCodeCatalogHttp 输出 JSON 目录
+func XCodeCatalogHttp(w http.ResponseWriter, r *http.Request) {
+	_ = ExportCatalog().WriteJSON(w)
+}
+
+// XCodeCatalogProtoHttp 输出 proto 枚举，包名由 package 参数指定，默认 xcode
+func XCodeCatalogProtoHttp(w http.ResponseWriter, r *http.Request) {
+	pkg := r.URL.Query().Get("package")
+	if pkg == "" {
+		pkg = "xcode"
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = ExportCatalog().WriteProto(w, pkg)
+}
@@ -0,0 +1,57 @@
+package xcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	_ = SystemCodeAdd(3, "invalid argument")
+	CodeAdds([]CodeInfo{
+		{CodeT: BusinessType, Code: 20001, Name: "ORDER_NOT_FOUND", Message: "order not found"},
+	})
+	catalog := ExportCatalog()
+	if catalog.Version != CatalogVersion || catalog.Checksum == "" {
+		t.Fatalf("unexpected catalog: %+v", catalog)
+	}
+	if first := catalog.Codes[0]; first.Type != "system" || first.Code != 0 || first.Name != "OK" {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+
+	var buf bytes.Buffer
+	if err := catalog.WriteProto(&buf, "demo.code"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"package demo.code;", "enum Code {", "ORDER_NOT_FOUND = 20001; // order not found", "UNKNOWN = 2;", "INVALID_ARGUMENT = 3;"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := catalog.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"name":"ORDER_NOT_FOUND"`) {
+		t.Fatalf("unexpected json: %s", buf.String())
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	first := SystemCodeAdd(9001, "conflict test")
+	if SystemCodeAdd(9001, "conflict test") != first {
+		t.Fatal("identical registration should return the registered status")
+	}
+	if err := Conflicts(); err != nil {
+		t.Fatalf("unexpected conflict: %v", err)
+	}
+	_ = SystemCodeAdd(9001, "another message")
+	if err := Conflicts(); err == nil || !strings.Contains(err.Error(), "9001") {
+		t.Fatalf("want conflict, got %v", err)
+	}
+	if SystemCode(9001).Message != "conflict test" {
+		t.Fatal("conflicting registration must not overwrite")
+	}
+	resetConflicts()
+}
@@ -0,0 +1,187 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/coder2z/g-server/xcode"
+	"go/format"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Code 错误码定义，Type 为 system 或 business，为空时按错误码大小判断
+type Code struct {
+	Name    string `toml:"name" yaml:"name"`
+	Code    uint32 `toml:"code" yaml:"code"`
+	Type    string `toml:"type" yaml:"type"`
+	Message string `toml:"message" yaml:"message"`
+	// Messages 各 locale 的本地化信息
+	Messages map[string]string `toml:"messages" yaml:"messages"`
+}
+
+// Definition 错误码定义文件
+//
+//	package = "codes"
+//	[[codes]]
+//	name = "USER_NOT_FOUND"
+//	code = 10001
+//	message = "user not found"
+//	messages = { zh-CN = "用户不存在" }
+type Definition struct {
+	Package string `toml:"package" yaml:"package"`
+	Codes   []Code `toml:"codes" yaml:"codes"`
+}
+
+// Load 按扩展名解析 .toml/.yaml/.yml 定义文件
+func Load(path string) (*Definition, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	def := &Definition{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(content, def)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(content, def)
+	default:
+		return nil, fmt.Errorf("unsupported definition file: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return def, def.Validate()
+}
+
+// Validate 检查名称、生成的常量名、错误码范围以及重复定义
+func (d *Definition) Validate() error {
+	names := make(map[string]bool, len(d.Codes))
+	values := make(map[string]bool, len(d.Codes))
+	// 生成文件中已有 Codes 变量
+	consts := map[string]string{"Codes": "Codes"}
+	for i := range d.Codes {
+		c := &d.Codes[i]
+		if c.Name == "" {
+			return fmt.Errorf("code %d: name is required", c.Code)
+		}
+		if c.Type == "" {
+			c.Type = "system"
+			if c.Code > xcode.CodeBreakUp {
+				c.Type = "business"
+			}
+		}
+		switch {
+		case c.Type == "system" && c.Code > xcode.CodeBreakUp:
+			return fmt.Errorf("%s: system code must less than %d", c.Name, xcode.CodeBreakUp)
+		case c.Type == "business" && c.Code < xcode.CodeBreakUp:
+			return fmt.Errorf("%s: business code must greater than %d", c.Name, xcode.CodeBreakUp)
+		case c.Type != "system" && c.Type != "business":
+			return fmt.Errorf("%s: unknown type %q", c.Name, c.Type)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate name %s", c.Name)
+		}
+		name := camelCase(c.Name)
+		switch {
+		case name == "":
+			return fmt.Errorf("%s: name must contain letters or digits", c.Name)
+		case unicode.IsDigit([]rune(name)[0]):
+			return fmt.Errorf("%s: constant %s must not start with a digit", c.Name, name)
+		case consts[name] != "":
+			return fmt.Errorf("%s: constant %s conflicts with %s", c.Name, name, consts[name])
+		}
+		consts[name] = c.Name
+		value := fmt.Sprintf("%s/%d", c.Type, c.Code)
+		if values[value] {
+			return fmt.Errorf("duplicate %s code %d", c.Type, c.Code)
+		}
+		names[c.Name], values[value] = true, true
+	}
+	return nil
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by xcodegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/coder2z/g-server/xcode"
+
+const (
+{{- range .Codes}}
+	// {{.Const}} {{.Comment}}
+	{{.Const}} uint32 = {{.Code.Code}}
+{{- end}}
+)
+
+// Codes 定义文件中的所有错误码，init 时通过 xcode.CodeAdds 注册
+var Codes = []xcode.CodeInfo{
+{{- range .Codes}}
+	{CodeT: xcode.{{.CodeT}}, Code: {{.Const}}, Name: {{printf "%q" .Name}}, Message: {{printf "%q" .Message}}
+	{{- with .Messages}}, Messages: map[string]string{ {{- range $locale, $message := .}}{{printf "%q" $locale}}: {{printf "%q" $message}}, {{end -}} }{{end}}},
+{{- end}}
+}
+
+func init() {
+	xcode.CodeAdds(Codes)
+}
+`))
+
+type goCode struct {
+	Code
+	Const   string
+	CodeT   string
+	Comment string // 合并为一行的 Message
+}
+
+// GenerateGo 生成错误码常量文件，pkg 为空时使用定义文件中的包名
+func GenerateGo(d *Definition, pkg string) ([]byte, error) {
+	if pkg == "" {
+		pkg = d.Package
+	}
+	if pkg == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	list := make([]goCode, 0, len(d.Codes))
+	for _, c := range d.Codes {
+		codeT := "SystemType"
+		if c.Type == "business" {
+			codeT = "BusinessType"
+		}
+		list = append(list, goCode{
+			Code:    c,
+			Const:   camelCase(c.Name),
+			CodeT:   codeT,
+			Comment: strings.Join(strings.Fields(c.Message), " "),
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Code.Code < list[j].Code.Code })
+
+	var buf bytes.Buffer
+	err := goTemplate.Execute(&buf, map[string]interface{}{"Package": pkg, "Codes": list})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// camelCase USER_NOT_FOUND -> UserNotFound
+func camelCase(name string) string {
+	var buf bytes.Buffer
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(strings.ToLower(part))
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+	return buf.String()
+}
@@ -0,0 +1,100 @@
+package codegen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xcodegen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomlFile := filepath.Join(dir, "codes.toml")
+	_ = ioutil.WriteFile(tomlFile, []byte(`package = "codes"
+[[codes]]
+name = "USER_NOT_FOUND"
+code = 10001
+message = "user not found"
+messages = { zh-CN = "用户不存在" }
+[[codes]]
+name = "QUOTA_EXCEEDED"
+code = 100
+message = "quota exceeded"
+`), 0644)
+	yamlFile := filepath.Join(dir, "codes.yaml")
+	_ = ioutil.WriteFile(yamlFile, []byte(`package: codes
+codes:
+  - name: USER_NOT_FOUND
+    code: 10001
+    message: user not found
+    messages:
+      zh-CN: 用户不存在
+  - name: QUOTA_EXCEEDED
+    code: 100
+    message: quota exceeded
+`), 0644)
+
+	var outputs []string
+	for _, file := range []string{tomlFile, yamlFile} {
+		def, err := Load(file)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		content, err := GenerateGo(def, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, string(content))
+	}
+	if outputs[0] != outputs[1] {
+		t.Fatalf("toml and yaml output differ:\n%s\n%s", outputs[0], outputs[1])
+	}
+	for _, want := range []string{
+		"package codes",
+		"UserNotFound uint32 = 10001",
+		"QuotaExceeded uint32 = 100",
+		`{CodeT: xcode.BusinessType, Code: UserNotFound, Name: "USER_NOT_FOUND", Message: "user not found", Messages: map[string]string{"zh-CN": "用户不存在"}}`,
+		`{CodeT: xcode.SystemType, Code: QuotaExceeded, Name: "QUOTA_EXCEEDED", Message: "quota exceeded"}`,
+	} {
+		if !strings.Contains(outputs[0], want) {
+			t.Fatalf("missing %q in:\n%s", want, outputs[0])
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []Definition{
+		{Codes: []Code{{Name: "A", Code: 10001}, {Name: "A", Code: 10002}}},
+		{Codes: []Code{{Name: "A", Code: 10001}, {Name: "B", Code: 10001}}},
+		{Codes: []Code{{Name: "A", Code: 100, Type: "business"}}},
+		{Codes: []Code{{Code: 100}}},
+		{Codes: []Code{{Name: "__", Code: 100}}},
+		{Codes: []Code{{Name: "404_NOT_FOUND", Code: 100}}},
+		{Codes: []Code{{Name: "USER_NOT_FOUND", Code: 100}, {Name: "user-not-found", Code: 101}}},
+		{Codes: []Code{{Name: "CODES", Code: 100}}},
+	}
+	for i, d := range cases {
+		if err := d.Validate(); err == nil {
+			t.Fatalf("case %d: want error", i)
+		}
+	}
+}
+
+func TestGenerateGoMultilineMessage(t *testing.T) {
+	d := &Definition{Package: "codes", Codes: []Code{{Name: "BAD_INPUT", Code: 100, Message: "bad input\nplease retry"}}}
+	content, err := GenerateGo(d, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// BadInput bad input please retry\n",
+		`Message: "bad input\nplease retry"`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("missing %q in:\n%s", want, content)
+		}
+	}
+}
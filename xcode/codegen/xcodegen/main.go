@@ -0,0 +1,34 @@
+// xcodegen 根据 TOML/YAML 错误码定义生成 Go 常量文件
+//
+//	//go:generate go run github.com/coder2z/g-server/xcode/codegen/xcodegen -in codes.toml -out codes.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/coder2z/g-server/xcode/codegen"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	in := flag.String("in", "codes.toml", "definition file, .toml/.yaml/.yml")
+	out := flag.String("out", "codes.go", "output go file")
+	pkg := flag.String("package", "", "package name, default to the package in definition file")
+	flag.Parse()
+
+	def, err := codegen.Load(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	content, err := codegen.GenerateGo(def, *pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(*out, content, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
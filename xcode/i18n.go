@@ -0,0 +1,120 @@
+package xcode
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Translation 错误码在某个 locale 下的信息
+type Translation struct {
+	Locale  string
+	Message string
+}
+
+// Bundle 翻译包，locale -> 错误码 -> 信息，错误码大于 CodeBreakUp 的为业务错误码
+type Bundle map[string]map[uint32]string
+
+var (
+	_translations sync.Map // codeKey -> map[string]string，注册错误码时提供的翻译，写时复制
+	translationMu sync.Mutex
+
+	bundleMu    sync.RWMutex
+	bundles     = make(map[string]Bundle)
+	bundleNames []string
+)
+
+// normalizeLocale zh_cn -> zh-CN
+func normalizeLocale(locale string) string {
+	parts := strings.Split(strings.Replace(strings.TrimSpace(locale), "_", "-", -1), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func setTranslation(codeT uint, code uint32, locale, message string) {
+	translationMu.Lock()
+	defer translationMu.Unlock()
+	key := codeKey{codeT, code}
+	messages := make(map[string]string)
+	if existing, ok := _translations.Load(key); ok {
+		for k, v := range existing.(map[string]string) {
+			messages[k] = v
+		}
+	}
+	messages[normalizeLocale(locale)] = message
+	_translations.Store(key, messages)
+}
+
+// SetBundle 设置名为 name 的翻译包，同名的翻译包会被整体替换，翻译包优先于注册时提供的翻译
+func SetBundle(name string, bundle Bundle) {
+	normalized := make(Bundle, len(bundle))
+	for locale, messages := range bundle {
+		normalized[normalizeLocale(locale)] = messages
+	}
+	bundleMu.Lock()
+	defer bundleMu.Unlock()
+	if _, ok := bundles[name]; !ok {
+		bundleNames = append(bundleNames, name)
+		sort.Strings(bundleNames)
+	}
+	bundles[name] = normalized
+}
+
+// RemoveBundle 删除翻译包
+func RemoveBundle(name string) {
+	bundleMu.Lock()
+	defer bundleMu.Unlock()
+	if _, ok := bundles[name]; !ok {
+		return
+	}
+	delete(bundles, name)
+	for i, n := range bundleNames {
+		if n == name {
+			bundleNames = append(bundleNames[:i], bundleNames[i+1:]...)
+			break
+		}
+	}
+}
+
+func lookupTranslation(code uint32, locale string) (string, bool) {
+	bundleMu.RLock()
+	for _, name := range bundleNames {
+		if msg, ok := bundles[name][locale][code]; ok {
+			bundleMu.RUnlock()
+			return msg, true
+		}
+	}
+	bundleMu.RUnlock()
+
+	codeT := uint(SystemType)
+	if _, ok := _codesBusiness.Load(code); ok {
+		codeT = BusinessType
+	}
+	if messages, ok := _translations.Load(codeKey{codeT, code}); ok {
+		msg, ok := messages.(map[string]string)[locale]
+		return msg, ok
+	}
+	return "", false
+}
+
+// Localize 按 locales 的顺序查找本地化信息，zh-CN 没有翻译时会尝试 zh
+func (s *spbStatus) Localize(locales ...string) (locale string, message string, ok bool) {
+	code := s.GetCodeAsUint32()
+	for _, l := range locales {
+		l = normalizeLocale(l)
+		if msg, ok := lookupTranslation(code, l); ok {
+			return l, msg, true
+		}
+		if i := strings.Index(l, "-"); i > 0 {
+			if msg, ok := lookupTranslation(code, l[:i]); ok {
+				return l[:i], msg, true
+			}
+		}
+	}
+	return "", "", false
+}
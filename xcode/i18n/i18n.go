@@ -0,0 +1,203 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/datasource/file"
+	"github.com/coder2z/g-server/xcode"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/yaml.v2"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptLanguageKey 客户端通过该 metadata 指定期望的语言，格式同 HTTP Accept-Language
+const AcceptLanguageKey = "accept-language"
+
+// Resolver 从请求上下文中解析期望的 locale，按优先级排序
+type Resolver func(ctx context.Context) []string
+
+type Config struct {
+	DefaultLocale string   `mapStructure:"default_locale"` // 客户端未指定或没有对应翻译时使用
+	Bundles       []string `mapStructure:"bundles"`        // 翻译包文件，支持 .toml/.yaml/.yml/.json
+	Watch         bool     `mapStructure:"watch"`          // 翻译包文件变化时自动重新加载
+
+	sources []io.Closer
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Watch: true,
+	}
+}
+
+// Load 加载配置中的所有翻译包，失败时关闭已加载的翻译包
+func (c *Config) Load() error {
+	for _, path := range c.Bundles {
+		source, err := LoadFile(path, c.Watch)
+		if err != nil {
+			_ = c.Close()
+			return err
+		}
+		c.sources = append(c.sources, source)
+	}
+	return nil
+}
+
+// Close 停止监听已加载的翻译包文件
+func (c *Config) Close() error {
+	for _, source := range c.sources {
+		_ = source.Close()
+	}
+	c.sources = nil
+	return nil
+}
+
+// Resolver 按 accept-language 解析，最后追加 DefaultLocale
+func (c *Config) Resolver() Resolver {
+	return func(ctx context.Context) []string {
+		locales := AcceptLanguage(ctx)
+		if c.DefaultLocale != "" {
+			locales = append(locales, c.DefaultLocale)
+		}
+		return locales
+	}
+}
+
+// AcceptLanguage 解析 metadata 中的 accept-language，如 zh-CN,zh;q=0.9,en;q=0.8
+func AcceptLanguage(ctx context.Context) []string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	return ParseAcceptLanguage(strings.Join(md.Get(AcceptLanguageKey), ","))
+}
+
+// ParseAcceptLanguage 按权重从高到低返回 locale，忽略 * 和权重为 0 的项
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	list := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			list = append(list, weighted{locale, q})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].q > list[j].q })
+	locales := make([]string, 0, len(list))
+	for _, w := range list {
+		locales = append(locales, w.locale)
+	}
+	return locales
+}
+
+// Decode 解析翻译包，内容为 locale -> 错误码 -> 信息
+//
+//	[zh-CN]
+//	10001 = "用户不存在"
+func Decode(content []byte, unmarshaler xcfg.Unmarshaler) (xcode.Bundle, error) {
+	raw := make(map[string]map[string]string)
+	if err := unmarshaler(content, &raw); err != nil {
+		return nil, err
+	}
+	bundle := make(xcode.Bundle, len(raw))
+	for locale, messages := range raw {
+		bundle[locale] = make(map[uint32]string, len(messages))
+		for key, message := range messages {
+			code, err := strconv.ParseUint(key, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("locale %s: invalid code %q", locale, key)
+			}
+			bundle[locale][uint32(code)] = message
+		}
+	}
+	return bundle, nil
+}
+
+// Load 从任意 datasource 加载名为 name 的翻译包，datasource 通知变化时重新加载
+func Load(name string, ds xcfg.DataSource, unmarshaler xcfg.Unmarshaler) error {
+	if err := reload(name, ds, unmarshaler); err != nil {
+		return err
+	}
+	if changed := ds.IsConfigChanged(); changed != nil {
+		go func() {
+			for range changed {
+				if err := reload(name, ds, unmarshaler); err != nil {
+					xlog.Error("Application Reload",
+						xlog.FieldComponentName("XCode"),
+						xlog.FieldMethod("XCode.I18n.Load"),
+						xlog.FieldName(name),
+						xlog.FieldErr(err),
+					)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+func reload(name string, ds xcfg.DataSource, unmarshaler xcfg.Unmarshaler) error {
+	content, err := ds.ReadConfig()
+	if err != nil {
+		return err
+	}
+	bundle, err := Decode(content, unmarshaler)
+	if err != nil {
+		return err
+	}
+	xcode.SetBundle(name, bundle)
+	return nil
+}
+
+// LoadFile 按扩展名解析翻译包文件，以文件路径命名，watch 时文件变化后自动重新加载，
+// 返回的 Closer 用于停止监听
+func LoadFile(path string, watch bool) (io.Closer, error) {
+	unmarshaler, err := unmarshalerFor(path)
+	if err != nil {
+		return nil, err
+	}
+	source := file.NewDataSource(path, watch)
+	if source == nil {
+		return nil, fmt.Errorf("invalid bundle path: %s", path)
+	}
+	if err := Load(path, source, unmarshaler); err != nil {
+		_ = source.Close()
+		return nil, err
+	}
+	return source, nil
+}
+
+func unmarshalerFor(path string) (xcfg.Unmarshaler, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return toml.Unmarshal, nil
+	case ".yaml", ".yml":
+		return yaml.Unmarshal, nil
+	case ".json":
+		return json.Unmarshal, nil
+	}
+	return nil, fmt.Errorf("unsupported bundle file: %s", path)
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-server/xcode"
+	"google.golang.org/grpc/metadata"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAcceptLanguage(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AcceptLanguageKey, "en;q=0.8, zh-CN, zh;q=0.9, *;q=0.1, fr;q=0"))
+	if got := AcceptLanguage(ctx); !reflect.DeepEqual(got, []string{"zh-CN", "zh", "en"}) {
+		t.Fatalf("unexpected locales: %v", got)
+	}
+	resolver := (&Config{DefaultLocale: "en"}).Resolver()
+	if got := resolver(context.Background()); !reflect.DeepEqual(got, []string{"en"}) {
+		t.Fatalf("unexpected default: %v", got)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	st := xcode.BusinessCodeAdd(40001, "order not found")
+	dir, err := ioutil.TempDir("", "i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "zh.toml")
+	_ = ioutil.WriteFile(path, []byte("[zh-CN]\n40001 = \"订单不存在\"\n"), 0644)
+
+	source, err := LoadFile(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer xcode.RemoveBundle(path)
+	defer source.Close()
+	if _, msg, ok := st.Localize("zh-CN"); !ok || msg != "订单不存在" {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+
+	// 修改文件后自动重新加载
+	_ = ioutil.WriteFile(path, []byte("[zh-CN]\n40001 = \"找不到订单\"\n"), 0644)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, msg, _ := st.Localize("zh-CN"); msg == "找不到订单" {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("bundle not reloaded")
+}
+
+func TestLoadFileClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "en.toml")
+	_ = ioutil.WriteFile(path, []byte("[en]\n40002 = \"ok\"\n"), 0644)
+
+	c := &Config{Bundles: []string{path, filepath.Join(dir, "missing.toml")}, Watch: true}
+	if err := c.Load(); err == nil {
+		t.Fatal("want missing bundle error")
+	}
+	if len(c.sources) != 0 {
+		t.Fatal("sources should be closed on error")
+	}
+
+	c.Bundles = c.Bundles[:1]
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	source := c.sources[0].(xcfg.DataSource)
+	_ = c.Close()
+	// 关闭后监听协程退出并关闭通知通道
+	select {
+	case _, ok := <-source.IsConfigChanged():
+		if ok {
+			t.Fatal("unexpected change notification")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher not stopped")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	if _, err := Decode([]byte(`{"zh-CN":{"abc":"x"}}`), json.Unmarshal); err == nil {
+		t.Fatal("want invalid code error")
+	}
+}
@@ -0,0 +1,28 @@
+package xcode
+
+import "testing"
+
+func TestLocalize(t *testing.T) {
+	st := BusinessCodeAdd(30001, "user not found", Translation{Locale: "zh_cn", Message: "用户不存在"})
+	CodeAdds([]CodeInfo{{CodeT: BusinessType, Code: 30002, Message: "user disabled", Messages: map[string]string{"zh": "用户已禁用"}}})
+
+	if locale, msg, ok := st.Localize("en-US", "zh-CN"); !ok || locale != "zh-CN" || msg != "用户不存在" {
+		t.Fatalf("unexpected localize: %s %s %v", locale, msg, ok)
+	}
+	// zh-TW 没有翻译时回退到 zh
+	if locale, msg, ok := BusinessCode(30002).Localize("zh-TW"); !ok || locale != "zh" || msg != "用户已禁用" {
+		t.Fatalf("unexpected fallback: %s %s %v", locale, msg, ok)
+	}
+
+	SetBundle("test", Bundle{"zh-CN": {30001: "找不到用户"}})
+	if _, msg, _ := st.Localize("zh-CN"); msg != "找不到用户" {
+		t.Fatalf("bundle should take precedence, got %s", msg)
+	}
+	RemoveBundle("test")
+	if _, msg, _ := st.Localize("zh-CN"); msg != "用户不存在" {
+		t.Fatalf("unexpected message after remove: %s", msg)
+	}
+	if _, _, ok := st.Localize("fr"); ok {
+		t.Fatal("unexpected fr translation")
+	}
+}
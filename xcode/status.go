@@ -2,16 +2,20 @@ package xcode
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/coder2z/g-saber/xjson"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"reflect"
 )
 
 type spbStatus struct {
@@ -33,7 +37,7 @@ func (s *spbStatus) Error() string {
 }
 
 func (s *spbStatus) GRPCStatus() *status.Status {
-	return status.New(codes.Code(s.Status.Code), s.Message)
+	return status.FromProto(s.Status)
 }
 
 // GetCodeAsBool ...
@@ -76,9 +80,9 @@ func (s *spbStatus) SetMsgf(format string, age ...interface{}) *spbStatus {
 func (s *spbStatus) GetDetailMessage(exts ...interface{}) string {
 	var buf bytes.Buffer
 	buf.WriteString(s.GetMessage(exts...))
-	for _, detail := range s.Details {
+	for _, detail := range s.UnpackDetails() {
 		buf.WriteByte('\n')
-		buf.WriteString(detail.String())
+		buf.WriteString(fmt.Sprintf("%v", detail))
 	}
 	return buf.String()
 }
@@ -136,17 +140,75 @@ func (s *spbStatus) WithDetails(details ...interface{}) (*spbStatus, error) {
 	return &spbStatus{Status: p}, nil
 }
 
+// marshalAny 非 proto 的详情先按 json 转换为 google.protobuf.Value，保证对端可以用 ptypes.UnmarshalAny 解析
 func marshalAny(obj interface{}) (*any.Any, error) {
-	typ := reflect.TypeOf(obj)
-	val := fmt.Sprintf("%+v", obj)
-
-	return &any.Any{TypeUrl: typ.Name(), Value: []byte(val)}, nil
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	value := &structpb.Value{}
+	if err := jsonpb.UnmarshalString(string(bs), value); err != nil {
+		return nil, err
+	}
+	return ptypes.MarshalAny(value)
 }
 
 func marshalAnyProtoMessage(pb proto.Message) (*any.Any, error) {
-	value, err := proto.Marshal(pb)
-	if err != nil {
-		return nil, err
+	return ptypes.MarshalAny(pb)
+}
+
+// UnpackDetails 解析所有详情，无法解析的详情以 error 返回
+func (s *spbStatus) UnpackDetails() []interface{} {
+	details := make([]interface{}, 0, len(s.Details))
+	for _, detail := range s.Details {
+		message := &ptypes.DynamicAny{}
+		if err := ptypes.UnmarshalAny(detail, message); err != nil {
+			details = append(details, err)
+			continue
+		}
+		details = append(details, message.Message)
+	}
+	return details
+}
+
+// findDetail 将第一个与 pb 同类型的详情解析到 pb 中
+func (s *spbStatus) findDetail(pb proto.Message) bool {
+	for _, detail := range s.Details {
+		if ptypes.Is(detail, pb) && ptypes.UnmarshalAny(detail, pb) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorInfo 错误原因详情
+func (s *spbStatus) ErrorInfo() (*errdetails.ErrorInfo, bool) {
+	info := &errdetails.ErrorInfo{}
+	return info, s.findDetail(info)
+}
+
+// RetryInfo 建议的重试间隔
+func (s *spbStatus) RetryInfo() (*errdetails.RetryInfo, bool) {
+	info := &errdetails.RetryInfo{}
+	return info, s.findDetail(info)
+}
+
+// BadRequest 请求参数错误详情
+func (s *spbStatus) BadRequest() (*errdetails.BadRequest, bool) {
+	badRequest := &errdetails.BadRequest{}
+	return badRequest, s.findDetail(badRequest)
+}
+
+// LocalizedMessage 本地化的错误信息，locale 为空时返回第一个，否则返回匹配该 locale 的
+func (s *spbStatus) LocalizedMessage(locale string) (*errdetails.LocalizedMessage, bool) {
+	for _, detail := range s.Details {
+		message := &errdetails.LocalizedMessage{}
+		if !ptypes.Is(detail, message) || ptypes.UnmarshalAny(detail, message) != nil {
+			continue
+		}
+		if locale == "" || message.Locale == locale {
+			return message, true
+		}
 	}
-	return &any.Any{TypeUrl: proto.MarshalTextString(pb), Value: value}, nil
+	return nil, false
 }
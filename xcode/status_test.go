@@ -0,0 +1,66 @@
+package xcode
+
+import (
+	"errors"
+	"github.com/golang/protobuf/ptypes"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestWithDetailsRoundTrip(t *testing.T) {
+	base := SystemCodeAdd(14, "unavailable")
+	st := base.MustWithDetails(
+		&errdetails.ErrorInfo{Reason: "DB_DOWN", Domain: "user"},
+		&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(time.Second)},
+		&errdetails.LocalizedMessage{Locale: "zh-CN", Message: "服务不可用"},
+		map[string]interface{}{"shard": 3},
+	)
+	if len(base.Details) != 0 {
+		t.Fatal("WithDetails must not modify the registered status")
+	}
+
+	// 经过 grpc status 转换后详情不能丢失
+	got := ExtractCodes(status.Convert(st).Err())
+	if got.GetCodeAsUint32() != st.GetCodeAsUint32() || got.Message != "unavailable" {
+		t.Fatalf("unexpected status: %v", got)
+	}
+	info, ok := got.ErrorInfo()
+	if !ok || info.Reason != "DB_DOWN" {
+		t.Fatalf("unexpected ErrorInfo: %v", info)
+	}
+	retry, ok := got.RetryInfo()
+	if !ok || retry.RetryDelay.Seconds != 1 {
+		t.Fatalf("unexpected RetryInfo: %v", retry)
+	}
+	if _, ok := got.BadRequest(); ok {
+		t.Fatal("unexpected BadRequest")
+	}
+	if msg, ok := got.LocalizedMessage("zh-CN"); !ok || msg.Message != "服务不可用" {
+		t.Fatalf("unexpected LocalizedMessage: %v", msg)
+	}
+	if _, ok := got.LocalizedMessage("en-US"); ok {
+		t.Fatal("unexpected en-US LocalizedMessage")
+	}
+
+	details := got.UnpackDetails()
+	if len(details) != 4 {
+		t.Fatalf("want 4 details, got %d", len(details))
+	}
+	value, ok := details[3].(*structpb.Value)
+	if !ok || value.GetStructValue().GetFields()["shard"].GetNumberValue() != 3 {
+		t.Fatalf("unexpected non-proto detail: %v", details[3])
+	}
+}
+
+func TestExtractCodes(t *testing.T) {
+	if ExtractCodes(nil) != OK {
+		t.Fatal("nil error must be OK")
+	}
+	st := ExtractCodes(errors.New("boom"))
+	if st.GetCodeAsUint32() != uint32(Unknown.Code) || st.Message != "boom" {
+		t.Fatalf("unexpected status: %v", st)
+	}
+}
@@ -31,9 +31,11 @@ var (
 )
 
 type CodeInfo struct {
-	CodeT   uint
-	Code    uint32
-	Message string
+	CodeT    uint
+	Code     uint32
+	Name     string // 可选，导出目录和生成代码时使用的名称，如 USER_NOT_FOUND
+	Message  string
+	Messages map[string]string // 可选，locale -> 本地化信息，如 {"zh-CN": "用户不存在"}
 }
 
 func XCodeSystemCodeHttp(w http.ResponseWriter, r *http.Request) {
@@ -94,7 +96,8 @@ func SystemCodeAdd(code uint32, message string) *spbStatus {
 	return add(SystemType, aid*10000+code, message)
 }
 
-func BusinessCodeAdd(code uint32, message string) *spbStatus {
+// BusinessCodeAdd 注册业务错误码，translations 为各 locale 的本地化信息
+func BusinessCodeAdd(code uint32, message string, translations ...Translation) *spbStatus {
 	if code < CodeBreakUp {
 		xlog.Panic("Application System Panic",
 			xlog.FieldErr(fmt.Errorf("customize code must less than 9999")),
@@ -102,15 +105,27 @@ func BusinessCodeAdd(code uint32, message string) *spbStatus {
 			xlog.FieldMethod("XCode.BusinessCodeAdd"),
 		)
 	}
-	return add(BusinessType, code, message)
+	s := add(BusinessType, code, message)
+	for _, t := range translations {
+		setTranslation(BusinessType, code, t.Locale, t.Message)
+	}
+	return s
 }
 
 func CodeAdds(data []CodeInfo) {
 	for _, datum := range data {
 		_ = add(datum.CodeT, datum.Code, datum.Message)
+		if datum.Name != "" {
+			setName(datum.CodeT, datum.Code, datum.Name)
+		}
+		for locale, message := range datum.Messages {
+			setTranslation(datum.CodeT, datum.Code, locale, message)
+		}
 	}
 }
 
+// add 注册错误码，相同的错误码和信息重复注册时返回已注册的对象，
+// 信息不同时保留先注册的并记录冲突，由 Conflicts 在启动时检查
 func add(codeT uint, code uint32, message string) *spbStatus {
 	s := &spbStatus{
 		&spb.Status{
@@ -119,26 +134,36 @@ func add(codeT uint, code uint32, message string) *spbStatus {
 			Details: make([]*any.Any, 0),
 		},
 	}
-	if codeT == SystemType {
-		_codesSystem.Store(code, s)
+	codes := registry(codeT)
+	if codes == nil {
+		return s
 	}
-	if codeT == BusinessType {
-		_codesBusiness.Store(code, s)
+	if actual, loaded := codes.LoadOrStore(code, s); loaded {
+		existing := actual.(*spbStatus)
+		if existing.Message == message {
+			return existing
+		}
+		addConflict(fmt.Errorf("%s code %d registered with %q, conflicts with %q", typeName(codeT), code, message, existing.Message))
 	}
 	return s
 }
 
+func registry(codeT uint) *sync.Map {
+	switch codeT {
+	case SystemType:
+		return &_codesSystem
+	case BusinessType:
+		return &_codesBusiness
+	}
+	return nil
+}
+
 // ExtractCodes cause from error to ecode.
+// 保留 status.FromError 得到的原始状态，包括所有详情
 func ExtractCodes(e error) *spbStatus {
 	if e == nil {
 		return OK
 	}
 	gst, _ := status.FromError(e)
-	return &spbStatus{
-		&spb.Status{
-			Code:    int32(gst.Code()),
-			Message: gst.Message(),
-			Details: make([]*any.Any, 0),
-		},
-	}
+	return &spbStatus{gst.Proto()}
 }
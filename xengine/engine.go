@@ -0,0 +1,282 @@
+package xengine
+
+import (
+	"context"
+	"fmt"
+	"github.com/coder2z/g-saber/xdefer"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-saber/xsignals"
+	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgovern"
+	"github.com/coder2z/g-server/xinvoker"
+	"github.com/coder2z/g-server/xregistry"
+	"sync"
+	"time"
+)
+
+// Server 由 Engine 托管生命周期的服务，例如 gRPC、HTTP 服务
+type Server interface {
+	// Serve 阻塞运行，直到服务退出
+	Serve() error
+	// Stop 立即停止服务
+	Stop() error
+	// GracefulStop 停止接收新请求，并等待正在处理的请求完成
+	GracefulStop(ctx context.Context) error
+	// Address 服务监听地址，用于服务注册
+	Address() string
+}
+
+// Hook 生命周期钩子
+type Hook func() error
+
+type entry struct {
+	server   Server
+	registry xregistry.Registry
+	options  []xregistry.Option
+}
+
+// Engine 应用生命周期管理：
+// 启动 检查错误码冲突 -> BeforeStart -> xinvoker.Init -> xgovern -> Serve -> Register -> AfterStart
+// 退出 BeforeStop -> Deregister -> GracefulStop -> xinvoker.Close -> AfterStop -> xdefer.Clean
+type Engine struct {
+	entries []*entry
+
+	beforeStart []Hook
+	afterStart  []Hook
+	beforeStop  []Hook
+	afterStop   []Hook
+
+	shutdownTimeout time.Duration
+	governOpts      []xgovern.Option
+	disableGovern   bool
+	disableSignal   bool
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		shutdownTimeout: defaultShutdownTimeout,
+		stopCh:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Serve 托管一个不需要注册到注册中心的服务
+func (e *Engine) Serve(s Server) *Engine {
+	return e.ServeWithRegistry(s, nil)
+}
+
+// ServeWithRegistry 托管一个服务，服务启动后使用 reg 注册，退出时最先注销
+func (e *Engine) ServeWithRegistry(s Server, reg xregistry.Registry, opts ...xregistry.Option) *Engine {
+	e.entries = append(e.entries, &entry{
+		server:   s,
+		registry: reg,
+		options:  opts,
+	})
+	return e
+}
+
+// BeforeStart 在初始化 invoker 之前执行，返回错误则终止启动
+func (e *Engine) BeforeStart(fns ...Hook) *Engine {
+	e.beforeStart = append(e.beforeStart, fns...)
+	return e
+}
+
+// AfterStart 在所有服务启动并注册之后执行，返回错误则开始退出
+func (e *Engine) AfterStart(fns ...Hook) *Engine {
+	e.afterStart = append(e.afterStart, fns...)
+	return e
+}
+
+// BeforeStop 在注销服务之前执行
+func (e *Engine) BeforeStop(fns ...Hook) *Engine {
+	e.beforeStop = append(e.beforeStop, fns...)
+	return e
+}
+
+// AfterStop 在关闭 invoker 之后执行
+func (e *Engine) AfterStop(fns ...Hook) *Engine {
+	e.afterStop = append(e.afterStop, fns...)
+	return e
+}
+
+// Stop 触发退出流程，可重复调用
+func (e *Engine) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+}
+
+// Run 启动应用并阻塞，直到收到退出信号、调用 Stop 或者服务异常退出
+func (e *Engine) Run() error {
+	xapp.PrintVersion()
+
+	// 错误码冲突会导致客户端解析出错误的信息，启动时拒绝
+	if err := xcode.Conflicts(); err != nil {
+		xlog.Error("Application Starting",
+			xlog.FieldComponentName("XEngine"),
+			xlog.FieldMethod("XEngine.Run"),
+			xlog.FieldErr(err),
+		)
+		return err
+	}
+
+	if err := e.runHooks("BeforeStart", e.beforeStart, true); err != nil {
+		return err
+	}
+
+	e.info("Application Starting", "XEngine.Run", "Invoker initialization")
+	if err := xinvoker.Init(); err != nil {
+		xlog.Error("Application Starting",
+			xlog.FieldComponentName("XEngine"),
+			xlog.FieldMethod("XEngine.Run"),
+			xlog.FieldDescription("Invoker initialization error"),
+			xlog.FieldErr(err),
+		)
+		// xinvoker.Init 失败时已经关闭了初始化过的 invoker
+		xdefer.Clean()
+		return err
+	}
+
+	if !e.disableGovern {
+		if err := xgovern.Check(e.governOpts...); err != nil {
+			xlog.Error("Application Starting",
+				xlog.FieldComponentName("XEngine"),
+				xlog.FieldMethod("XEngine.Run"),
+				xlog.FieldDescription("Govern config error"),
+				xlog.FieldErr(err),
+			)
+			e.release()
+			return err
+		}
+		go xgovern.Run(e.governOpts...)
+	}
+
+	serveErr := make(chan error, len(e.entries))
+	for _, en := range e.entries {
+		go func(en *entry) {
+			e.info("Application Starting", "XEngine.Serve", fmt.Sprintf("Server running :%s", en.server.Address()))
+			serveErr <- en.server.Serve()
+		}(en)
+	}
+
+	for _, en := range e.entries {
+		if en.registry == nil {
+			continue
+		}
+		opts := append([]xregistry.Option{xregistry.Address(en.server.Address())}, en.options...)
+		en.registry.Register(opts...)
+	}
+
+	var cause error
+	if err := e.runHooks("AfterStart", e.afterStart, true); err != nil {
+		cause = err
+		e.Stop()
+	}
+
+	var signalCh <-chan struct{}
+	if !e.disableSignal {
+		signalCh = xsignals.SetupSignalHandler()
+	}
+
+	select {
+	case <-signalCh:
+		e.info("Application Stopping", "XEngine.Run", "Receive shutdown signal")
+	case <-e.stopCh:
+		e.info("Application Stopping", "XEngine.Run", "Engine stop called")
+	case err := <-serveErr:
+		if err != nil {
+			cause = err
+			xlog.Error("Application Stopping",
+				xlog.FieldComponentName("XEngine"),
+				xlog.FieldMethod("XEngine.Run"),
+				xlog.FieldDescription("Server exit unexpectedly"),
+				xlog.FieldErr(err),
+			)
+		}
+	}
+
+	e.shutdown()
+	return cause
+}
+
+func (e *Engine) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.shutdownTimeout)
+	defer cancel()
+
+	_ = e.runHooks("BeforeStop", e.beforeStop, false)
+
+	// 先注销，避免新的流量进入
+	for _, en := range e.entries {
+		if en.registry == nil {
+			continue
+		}
+		e.info("Application Stopping", "XEngine.Deregister", fmt.Sprintf("Server deregister :%s", en.server.Address()))
+		en.registry.Close()
+	}
+
+	// 等待处理中的请求完成，超时则强制关闭
+	var wg sync.WaitGroup
+	for _, en := range e.entries {
+		wg.Add(1)
+		go func(s Server) {
+			defer wg.Done()
+			e.info("Application Stopping", "XEngine.GracefulStop", fmt.Sprintf("Server graceful stop :%s", s.Address()))
+			if err := s.GracefulStop(ctx); err != nil {
+				xlog.Warn("Application Stopping",
+					xlog.FieldComponentName("XEngine"),
+					xlog.FieldMethod("XEngine.GracefulStop"),
+					xlog.FieldDescription(fmt.Sprintf("Server graceful stop failed, force stop :%s", s.Address())),
+					xlog.FieldErr(err),
+				)
+				_ = s.Stop()
+			}
+		}(en.server)
+	}
+	wg.Wait()
+
+	e.info("Application Stopping", "XEngine.Shutdown", "Invoker close")
+	_ = xinvoker.Close()
+
+	_ = e.runHooks("AfterStop", e.afterStop, false)
+
+	xdefer.Clean()
+	e.info("Application Stopping", "XEngine.Shutdown", "Application shutdown")
+}
+
+// release 服务启动前失败时关闭 invoker 并执行 xdefer 中注册的清理
+func (e *Engine) release() {
+	e.info("Application Stopping", "XEngine.Release", "Invoker close")
+	_ = xinvoker.Close()
+	xdefer.Clean()
+}
+
+func (e *Engine) runHooks(stage string, hooks []Hook, abort bool) error {
+	for _, fn := range hooks {
+		if err := fn(); err != nil {
+			xlog.Error("Application Hook Error",
+				xlog.FieldComponentName("XEngine"),
+				xlog.FieldMethod("XEngine."+stage),
+				xlog.FieldErr(err),
+			)
+			if abort {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Engine) info(msg, method, description string) {
+	xlog.Info(msg,
+		xlog.FieldComponentName("XEngine"),
+		xlog.FieldMethod(method),
+		xlog.FieldDescription(description),
+	)
+}
@@ -0,0 +1,184 @@
+package xengine
+
+import (
+	"context"
+	"errors"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xdefer"
+	"github.com/coder2z/g-server/xinvoker"
+	"github.com/coder2z/g-server/xregistry"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	sync.Mutex
+	steps []string
+}
+
+func (r *recorder) add(step string) {
+	r.Lock()
+	defer r.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+type fakeServer struct {
+	r    *recorder
+	done chan struct{}
+}
+
+func (s *fakeServer) Serve() error {
+	<-s.done
+	return nil
+}
+
+func (s *fakeServer) Stop() error {
+	s.r.add("stop")
+	return nil
+}
+
+func (s *fakeServer) GracefulStop(ctx context.Context) error {
+	s.r.add("graceful")
+	close(s.done)
+	return nil
+}
+
+func (s *fakeServer) Address() string {
+	return "127.0.0.1:0"
+}
+
+type fakeRegistry struct {
+	r       *recorder
+	options xregistry.Options
+}
+
+func (f *fakeRegistry) Register(ops ...xregistry.Option) {
+	for _, o := range ops {
+		o(&f.options)
+	}
+	f.r.add("register")
+}
+
+func (f *fakeRegistry) Close() {
+	f.r.add("deregister")
+}
+
+type fakeInvoker struct {
+	xinvoker.Base
+	r   *recorder
+	key string
+	err error
+}
+
+func (i *fakeInvoker) Init(opts ...xinvoker.Option) error {
+	i.r.add("init:" + i.key)
+	return i.err
+}
+
+func (i *fakeInvoker) Close(opts ...xinvoker.Option) error {
+	i.r.add("close:" + i.key)
+	return nil
+}
+
+// cleaned xdefer.Clean 的执行次数
+var cleaned int
+
+func init() {
+	xdefer.Register(func() error {
+		cleaned++
+		return nil
+	})
+}
+
+func TestEngineLifecycle(t *testing.T) {
+	r := &recorder{}
+	xinvoker.Register(&fakeInvoker{r: r, key: "a"}, &fakeInvoker{r: r, key: "b"})
+
+	reg := &fakeRegistry{r: r}
+	e := New(WithoutGovern(), WithoutSignal(), WithShutdownTimeout(time.Second))
+	e.ServeWithRegistry(&fakeServer{r: r, done: make(chan struct{})}, reg, xregistry.ServiceName("demo")).
+		BeforeStart(func() error { r.add("beforeStart"); return nil }).
+		AfterStart(func() error { r.add("afterStart"); e.Stop(); return nil }).
+		BeforeStop(func() error { r.add("beforeStop"); return nil }).
+		AfterStop(func() error { r.add("afterStop"); return nil })
+
+	if err := e.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"beforeStart", "init:a", "init:b", "register", "afterStart",
+		"beforeStop", "deregister", "graceful", "close:b", "close:a", "afterStop",
+	}
+	if !reflect.DeepEqual(r.steps, want) {
+		t.Fatalf("steps = %v, want %v", r.steps, want)
+	}
+	if reg.options.Address != "127.0.0.1:0" || reg.options.ServiceName != "demo" {
+		t.Fatalf("unexpected register options %+v", reg.options)
+	}
+}
+
+func TestEngineBeforeStartError(t *testing.T) {
+	e := New(WithoutGovern(), WithoutSignal())
+	e.BeforeStart(func() error { return errors.New("boom") })
+	if err := e.Run(); err == nil {
+		t.Fatal("expect before start error")
+	}
+}
+
+func TestEngineInvokerInitError(t *testing.T) {
+	r := &recorder{}
+	ivk := &fakeInvoker{r: r, key: "broken", err: errors.New("dial refused")}
+	xinvoker.Register(ivk)
+	defer func() { ivk.err = nil }()
+
+	e := New(WithoutGovern(), WithoutSignal())
+	e.ServeWithRegistry(&fakeServer{r: r, done: make(chan struct{})}, &fakeRegistry{r: r})
+	if err := e.Run(); err == nil || !strings.Contains(err.Error(), "dial refused") {
+		t.Fatalf("err = %v", err)
+	}
+	for _, step := range r.steps {
+		if step == "register" {
+			t.Fatalf("steps = %v", r.steps)
+		}
+	}
+	// 失败的 invoker 也会关闭
+	if n := len(r.steps); n < 2 || r.steps[n-2] != "init:broken" || r.steps[n-1] != "close:broken" {
+		t.Fatalf("steps = %v", r.steps)
+	}
+	if cleaned == 0 {
+		t.Fatal("xdefer not cleaned after invoker init error")
+	}
+}
+
+func TestEngineGovernConfigError(t *testing.T) {
+	_ = xcfg.Apply(map[string]interface{}{
+		"app": map[string]interface{}{
+			"govern": map[string]interface{}{
+				"auth": map[string]interface{}{"type": "basic", "username": "admin"},
+			},
+		},
+	})
+	r := &recorder{}
+	ir := &recorder{}
+	xinvoker.Register(&fakeInvoker{r: ir, key: "govern"})
+	before := cleaned
+	e := New(WithoutSignal())
+	e.ServeWithRegistry(&fakeServer{r: r, done: make(chan struct{})}, &fakeRegistry{r: r})
+	if err := e.Run(); err == nil || !strings.Contains(err.Error(), "basic auth") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(r.steps) != 0 {
+		t.Fatalf("steps = %v", r.steps)
+	}
+	// 服务启动前失败也要关闭已经初始化的 invoker 并执行 xdefer
+	if want := []string{"init:govern", "close:govern"}; !reflect.DeepEqual(ir.steps, want) {
+		t.Fatalf("invoker steps = %v, want %v", ir.steps, want)
+	}
+	if cleaned == before {
+		t.Fatal("xdefer not cleaned after govern config error")
+	}
+}
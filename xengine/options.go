@@ -0,0 +1,40 @@
+package xengine
+
+import (
+	"github.com/coder2z/g-server/xgovern"
+	"time"
+)
+
+const (
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+type Option func(e *Engine)
+
+// WithShutdownTimeout 优雅退出的最长等待时间，超时后强制关闭服务
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(e *Engine) {
+		e.shutdownTimeout = timeout
+	}
+}
+
+// WithGovern 启动 xgovern 治理服务时使用的配置
+func WithGovern(opts ...xgovern.Option) Option {
+	return func(e *Engine) {
+		e.governOpts = opts
+	}
+}
+
+// WithoutGovern 不启动 xgovern 治理服务
+func WithoutGovern() Option {
+	return func(e *Engine) {
+		e.disableGovern = true
+	}
+}
+
+// WithoutSignal 不监听退出信号，由调用方通过 Stop 退出
+func WithoutSignal() Option {
+	return func(e *Engine) {
+		e.disableSignal = true
+	}
+}
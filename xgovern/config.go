@@ -9,6 +9,21 @@ type Config struct {
 	Host    string `mapStructure:"host"`
 	Port    int    `mapStructure:"port"`
 	Network string `mapStructure:"network"`
+
+	Auth        *AuthConfig         `mapStructure:"auth"`         // 认证方式，为空时不认证
+	AllowCIDRs  []string            `mapStructure:"allow_cidrs"`  // 允许访问的来源网段，如 10.0.0.0/8, 127.0.0.1/32，为空时不限制
+	PublicPaths []string            `mapStructure:"public_paths"` // 不需要认证的路径，如 /debug/health, /metrics，仍受网段限制
+	MaskKeys    []string            `mapStructure:"mask_keys"`    // /debug/env, /debug/config 中需要打码的 key，不区分大小写，支持 * 通配
+	Disabled    map[string][]string `mapStructure:"disabled"`     // 按 AppMode 禁用的路径，如 prod = ["/debug/pprof*", "/debug/env"]
+	Audit       bool                `mapStructure:"audit"`        // 是否记录访问日志
+}
+
+// AuthConfig basic 使用 Username/Password，bearer 使用 Tokens 中任意一个
+type AuthConfig struct {
+	Type     string   `mapStructure:"type"` // basic, bearer
+	Username string   `mapStructure:"username"`
+	Password string   `mapStructure:"password"`
+	Tokens   []string `mapStructure:"tokens"`
 }
 
 type Option func(c *Config)
@@ -22,6 +37,11 @@ func DefaultConfig() *Config {
 		Host:    host,
 		Port:    port,
 		Network: "tcp",
+		MaskKeys: []string{
+			"*password*", "*passwd*", "*secret*", "*token*", "*credential*",
+			"*access_key*", "*accesskey*", "*private_key*", "*dsn*",
+		},
+		Audit: true,
 	}
 }
 
@@ -2,6 +2,7 @@ package xgovern
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/coder2z/g-saber/xcfg"
@@ -11,6 +12,8 @@ import (
 	"github.com/coder2z/g-saber/xtime"
 	"github.com/coder2z/g-server/xapp"
 	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xhealth"
+	"github.com/coder2z/g-server/xlogger"
 	"github.com/coder2z/g-server/xmonitor"
 	"net/http"
 	"net/http/pprof"
@@ -31,6 +34,8 @@ type healthStats struct {
 	Time       string `json:"time,omitempty"`
 	Err        string `json:"err,omitempty"`
 	Status     string `json:"status,omitempty"`
+
+	Checks []xhealth.Result `json:"checks"`
 }
 
 type h map[string]func(w http.ResponseWriter, r *http.Request)
@@ -43,9 +48,13 @@ var (
 	once         = sync.Once{}
 )
 
+// Run 注册当前 AppMode 下启用的路径
 func (hm h) Run(hs *http.ServeMux) {
+	c := GovernConfig()
 	for s, f := range hm {
-		hs.HandleFunc(s, f)
+		if c.Enabled(s) {
+			hs.HandleFunc(s, f)
+		}
 	}
 }
 
@@ -68,30 +77,43 @@ func init() {
 
 	HandleFunc("/debug/code/business", xcode.XCodeBusinessCodeHttp)
 	HandleFunc("/debug/code/system", xcode.XCodeSystemCodeHttp)
+	HandleFunc("/debug/code/catalog", xcode.XCodeCatalogHttp)
+	HandleFunc("/debug/code/catalog.proto", xcode.XCodeCatalogProtoHttp)
 
 	HandleFunc("/metrics", xmonitor.MonitorPrometheusHttp)
 
 	HandleFunc("/debug/env", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
-		_ = xjson.NewEncoder(w).Encode(os.Environ())
+		_ = xjson.NewEncoder(w).Encode(GovernConfig().MaskEnv(os.Environ()))
 	})
 
 	HandleFunc("/debug/list", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		list := make([]string, 0)
+		c := GovernConfig()
 		for s, _ := range HandleFuncs {
-			list = append(list, s)
+			if c.Enabled(s) {
+				list = append(list, s)
+			}
 		}
 		_ = xjson.NewEncoder(w).Encode(list)
 	})
 
 	HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
-		mm := xcfg.Traverse(".")
+		mm := GovernConfig().MaskConfig(xcfg.Traverse("."))
 		w.WriteHeader(200)
-		_ = xjson.NewEncoder(w).Encode(mm)
+		_ = json.NewEncoder(w).Encode(mm)
 	})
 
+	HandleFunc("/debug/log/level", xlogger.LevelHttp)
+	HandleFunc("/debug/log/payload", xlogger.PayloadHttp)
+
+	HandleFunc("/debug/health/live", xhealth.LivenessHttp)
+	HandleFunc("/debug/health/ready", xhealth.ReadinessHttp)
+
+	// 应用信息和 readiness 检查结果，未就绪时返回 503
 	HandleFunc("/debug/health", func(w http.ResponseWriter, r *http.Request) {
+		report := xhealth.Ready()
 		serverStats := healthStats{
 			IP:         xapp.HostIP(),
 			Hostname:   xapp.HostName(),
@@ -104,9 +126,16 @@ func init() {
 			Time:       xtime.Now().Format("2006-01-02 15:04:05"),
 			Err:        "",
 			Status:     "SUCCESS",
+			Checks:     report.Checks,
 		}
-		w.WriteHeader(200)
-		_ = xjson.NewEncoder(w).Encode(serverStats)
+		if !report.Up() {
+			serverStats.Status = "FAILURE"
+			serverStats.Err = "dependencies not ready"
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(200)
+		}
+		_ = json.NewEncoder(w).Encode(serverStats)
 	})
 }
 
@@ -117,6 +146,16 @@ func GetServer() *http.ServeMux {
 	return handle
 }
 
+// Check 校验安全配置，Run 在后台运行，配置错误时只能记录日志，调用方应在启动前检查
+func Check(opts ...Option) error {
+	c := *GovernConfig()
+	for _, opt := range opts {
+		opt(&c)
+	}
+	_, err := newGuard(&c, http.NotFoundHandler())
+	return err
+}
+
 func Run(opts ...Option) {
 	once.Do(func() {
 		c := GovernConfig()
@@ -127,9 +166,20 @@ func Run(opts ...Option) {
 
 		HandleFuncs.Run(GetServer())
 
+		g, err := newGuard(c, handle)
+		if err != nil {
+			xlog.Error("Application Starting",
+				xlog.FieldComponentName("XGovern"),
+				xlog.FieldMethod("XGovern.Run"),
+				xlog.FieldDescription("Govern security config error"),
+				xlog.FieldErr(err),
+			)
+			return
+		}
+
 		server = &http.Server{
 			Addr:    c.Address(),
-			Handler: handle,
+			Handler: g,
 		}
 
 		xlog.Info("Application Starting",
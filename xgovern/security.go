@@ -0,0 +1,264 @@
+package xgovern
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/coder2z/g-saber/xcast"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xapp"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+
+	maskedValue = "******"
+)
+
+// Enabled 路径在当前 AppMode 下是否启用
+func (config Config) Enabled(p string) bool {
+	for _, pattern := range config.Disabled[strings.ToLower(xapp.AppMode())] {
+		if matchPath(pattern, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPath 以 * 结尾的 pattern 按前缀匹配，如 /debug/pprof* 匹配 /debug/pprof/profile
+func matchPath(pattern, p string) bool {
+	if strings.HasSuffix(pattern, "*") && strings.HasPrefix(p, strings.TrimSuffix(pattern, "*")) {
+		return true
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+// Masked key 是否需要打码
+func (config Config) Masked(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range config.MaskKeys {
+		if ok, _ := path.Match(strings.ToLower(pattern), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskEnv 对 KEY=VALUE 形式的环境变量打码
+func (config Config) MaskEnv(environ []string) []string {
+	res := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if i := strings.Index(kv, "="); i > 0 && config.Masked(kv[:i]) {
+			kv = kv[:i+1] + maskedValue
+		}
+		res = append(res, kv)
+	}
+	return res
+}
+
+// MaskConfig 对 xcfg.Traverse 得到的配置打码，数组中的表按所在 key 拼接后匹配
+func (config Config) MaskConfig(data map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		res[k] = config.mask(k, v)
+	}
+	return res
+}
+
+func (config Config) mask(key string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		res := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			res = append(res, config.mask(key, item))
+		}
+		return res
+	case []map[string]interface{}:
+		res := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			res = append(res, config.mask(key, item))
+		}
+		return res
+	}
+	if m, err := xcast.ToStringMapE(v); err == nil {
+		res := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			res[k] = config.mask(key+"."+k, item)
+		}
+		return res
+	}
+	if config.Masked(key) {
+		return maskedValue
+	}
+	return v
+}
+
+// guard 依次检查来源网段、认证，并记录访问日志
+type guard struct {
+	config *Config
+	nets   []*net.IPNet
+	next   http.Handler
+}
+
+func newGuard(config *Config, next http.Handler) (*guard, error) {
+	g := &guard{config: config, next: next}
+	for _, cidr := range config.AllowCIDRs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("allow_cidrs: %w", err)
+		}
+		g.nets = append(g.nets, ipNet)
+	}
+	if a := config.Auth; a != nil {
+		switch a.Type {
+		case "":
+		case AuthBasic:
+			if a.Username == "" || a.Password == "" {
+				return nil, fmt.Errorf("basic auth requires username and password")
+			}
+		case AuthBearer:
+			if len(a.Tokens) == 0 {
+				return nil, fmt.Errorf("bearer auth requires tokens")
+			}
+		default:
+			return nil, fmt.Errorf("unknown auth type %q", a.Type)
+		}
+	}
+	return g, nil
+}
+
+func (g *guard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	beg := time.Now()
+	ip := remoteIP(r)
+	rw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	user, ok := g.authenticate(r)
+
+	switch {
+	case !g.allowed(ip):
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	case !ok:
+		if g.config.Auth.Type == AuthBasic {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="govern"`)
+		} else {
+			rw.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	default:
+		g.next.ServeHTTP(rw, r)
+	}
+
+	if !g.config.Audit {
+		return
+	}
+	fields := []xlog.Field{
+		xlog.FieldComponentName("XGovern"),
+		xlog.FieldMethod(r.Method + " " + r.URL.Path),
+		xlog.FieldCode(int32(rw.status)),
+		xlog.FieldCost(time.Since(beg)),
+		xlog.FieldPeerIP(ip),
+		xlog.FieldName(user),
+	}
+	if rw.status == http.StatusUnauthorized || rw.status == http.StatusForbidden {
+		xlog.Warn("Govern Access Denied", fields...)
+		return
+	}
+	xlog.Info("Govern Access", fields...)
+}
+
+func (g *guard) allowed(ip string) bool {
+	if len(g.nets) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range g.nets {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *guard) public(p string) bool {
+	if g.config.Auth == nil || g.config.Auth.Type == "" {
+		return true
+	}
+	for _, pattern := range g.config.PublicPaths {
+		if matchPath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate 返回认证通过的用户，bearer 认证不区分用户，返回 token 序号；不需要认证时直接通过
+func (g *guard) authenticate(r *http.Request) (string, bool) {
+	if g.public(r.URL.Path) {
+		return "", true
+	}
+	a := g.config.Auth
+	switch a.Type {
+	case AuthBasic:
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return username, false
+		}
+		// 两项都比较，避免根据耗时判断用户名是否正确
+		u := subtle.ConstantTimeCompare([]byte(username), []byte(a.Username))
+		p := subtle.ConstantTimeCompare([]byte(password), []byte(a.Password))
+		return username, u&p == 1
+	case AuthBearer:
+		h := r.Header.Get("Authorization")
+		if len(h) < 7 || !strings.EqualFold(h[:7], "Bearer ") {
+			return "", false
+		}
+		token := []byte(strings.TrimSpace(h[7:]))
+		for i, t := range a.Tokens {
+			if subtle.ConstantTimeCompare(token, []byte(t)) == 1 {
+				return fmt.Sprintf("token#%d", i), true
+			}
+		}
+	}
+	return "", false
+}
+
+// remoteIP 只信任连接地址，不使用可伪造的 X-Forwarded-For
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush pprof/trace 等流式输出需要
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,109 @@
+package xgovern
+
+import (
+	"github.com/coder2z/g-server/xapp"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	c := DefaultConfig()
+	c.AllowCIDRs = []string{"10.0.0.0/8", "192.168.1.1"}
+	c.PublicPaths = []string{"/debug/health"}
+	c.Auth = &AuthConfig{Type: AuthBasic, Username: "admin", Password: "secret"}
+	g, err := newGuard(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		path   string
+		user   string
+		pass   string
+		want   int
+	}{
+		{"denied ip", "172.16.0.1:1234", "/debug/env", "admin", "secret", http.StatusForbidden},
+		{"no credentials", "10.1.2.3:1234", "/debug/env", "", "", http.StatusUnauthorized},
+		{"wrong password", "10.1.2.3:1234", "/debug/env", "admin", "x", http.StatusUnauthorized},
+		{"ok", "192.168.1.1:1234", "/debug/env", "admin", "secret", http.StatusOK},
+		{"public", "10.1.2.3:1234", "/debug/health", "", "", http.StatusOK},
+		{"public denied ip", "172.16.0.1:1234", "/debug/health", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		r.RemoteAddr = tt.remote
+		if tt.user != "" {
+			r.SetBasicAuth(tt.user, tt.pass)
+		}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestGuardBearer(t *testing.T) {
+	c := DefaultConfig()
+	c.Auth = &AuthConfig{Type: AuthBearer, Tokens: []string{"t1", "t2"}}
+	g, err := newGuard(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for token, want := range map[string]int{"t2": http.StatusOK, "t3": http.StatusUnauthorized} {
+		r := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("token %s: got %d, want %d", token, w.Code, want)
+		}
+	}
+
+	if _, err := newGuard(&Config{Auth: &AuthConfig{Type: AuthBearer}}, nil); err == nil {
+		t.Error("bearer auth without tokens should fail")
+	}
+	if _, err := newGuard(&Config{AllowCIDRs: []string{"10.0.0.0/33"}}, nil); err == nil {
+		t.Error("invalid cidr should fail")
+	}
+}
+
+func TestMask(t *testing.T) {
+	c := DefaultConfig()
+	env := c.MaskEnv([]string{"DB_PASSWORD=123", "HOME=/root", "API_TOKEN=a=b"})
+	if env[0] != "DB_PASSWORD="+maskedValue || env[1] != "HOME=/root" || env[2] != "API_TOKEN="+maskedValue {
+		t.Fatalf("env = %v", env)
+	}
+
+	conf := c.MaskConfig(map[string]interface{}{
+		"mysql.main.password": "123",
+		"mysql.main.addr":     "127.0.0.1",
+		"sms.accounts": []interface{}{
+			map[string]interface{}{"name": "a", "secret": "x"},
+		},
+	})
+	if conf["mysql.main.password"] != maskedValue || conf["mysql.main.addr"] != "127.0.0.1" {
+		t.Fatalf("config = %v", conf)
+	}
+	account := conf["sms.accounts"].([]interface{})[0].(map[string]interface{})
+	if account["secret"] != maskedValue || account["name"] != "a" {
+		t.Fatalf("account = %v", account)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	c := DefaultConfig()
+	c.Disabled = map[string][]string{
+		strings.ToLower(xapp.AppMode()): {"/debug/pprof*", "/debug/env"},
+		"mode-not-running":              {"/debug/config"},
+	}
+	for p, want := range map[string]bool{"/debug/pprof/profile": false, "/debug/env": false, "/debug/config": true} {
+		if got := c.Enabled(p); got != want {
+			t.Errorf("%s: got %v, want %v", p, got, want)
+		}
+	}
+}
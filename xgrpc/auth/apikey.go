@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+)
+
+type APIKey struct {
+	Key  string `mapStructure:"key"`
+	Name string `mapStructure:"name"` // 持有该 key 的调用方名称
+}
+
+type APIKeyConfig struct {
+	Header string   `mapStructure:"header"` // 携带 key 的 metadata，默认 x-api-key
+	Keys   []APIKey `mapStructure:"keys"`
+}
+
+func DefaultAPIKeyConfig() *APIKeyConfig {
+	return &APIKeyConfig{Header: "x-api-key"}
+}
+
+// APIKeyVerifier 静态 API key 认证
+type APIKeyVerifier struct {
+	header string
+	keys   []APIKey
+}
+
+func NewAPIKeyVerifier(config *APIKeyConfig) *APIKeyVerifier {
+	header := config.Header
+	if header == "" {
+		header = DefaultAPIKeyConfig().Header
+	}
+	return &APIKeyVerifier{header: header, keys: config.Keys}
+}
+
+func (v *APIKeyVerifier) Verify(ctx context.Context) (*Principal, error) {
+	key, ok := fromMetadata(ctx, v.header)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	for _, k := range v.keys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+			return &Principal{Type: TypeAPIKey, Name: k.Name, Subject: k.Name}, nil
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/metadata"
+	"path"
+	"strings"
+)
+
+const (
+	TypeJWT    = "jwt"
+	TypeAPIKey = "apikey"
+	TypeMTLS   = "mtls"
+)
+
+var (
+	// ErrNoCredentials 请求中没有该认证方式所需的凭证，Chain 会继续尝试下一个认证方式
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials 凭证校验失败
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal 认证通过的调用方身份
+type Principal struct {
+	Type    string                 `json:"type"`    // jwt, apikey, mtls
+	Name    string                 `json:"name"`    // 调用方名称，jwt 取 app_name 或 sub，apikey 取配置的名称，mtls 取证书 CN
+	Subject string                 `json:"subject"` // jwt sub，mtls 证书 Subject
+	Claims  map[string]interface{} `json:"claims,omitempty"`
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 获取认证拦截器放入的调用方身份
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// Verifier 从请求的 metadata 或连接信息中校验调用方身份
+// 请求中没有对应凭证时返回 ErrNoCredentials
+type Verifier interface {
+	Verify(ctx context.Context) (*Principal, error)
+}
+
+type chain []Verifier
+
+// Chain 按顺序尝试多个认证方式，使用第一个找到凭证的认证方式的结果
+func Chain(verifiers ...Verifier) Verifier {
+	return chain(verifiers)
+}
+
+func (c chain) Verify(ctx context.Context) (*Principal, error) {
+	for _, v := range c {
+		p, err := v.Verify(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+type Config struct {
+	Verifiers   []string      `mapStructure:"verifiers"`    // 按顺序启用的认证方式: jwt, apikey, mtls
+	SkipMethods []string      `mapStructure:"skip_methods"` // 不需要认证的方法，支持 /pkg.Service/* 形式
+	JWT         *JWTConfig    `mapStructure:"jwt"`
+	APIKey      *APIKeyConfig `mapStructure:"apikey"`
+	MTLS        *MTLSConfig   `mapStructure:"mtls"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		SkipMethods: []string{"/grpc.health.v1.Health/*", "/grpc.reflection.v1alpha.ServerReflection/*"},
+		JWT:         DefaultJWTConfig(),
+		APIKey:      DefaultAPIKeyConfig(),
+		MTLS:        &MTLSConfig{},
+	}
+}
+
+// New 按配置创建认证方式链
+func New(config *Config) (Verifier, error) {
+	verifiers := make([]Verifier, 0, len(config.Verifiers))
+	for _, name := range config.Verifiers {
+		switch name {
+		case TypeJWT:
+			v, err := NewJWTVerifier(config.JWT)
+			if err != nil {
+				return nil, err
+			}
+			verifiers = append(verifiers, v)
+		case TypeAPIKey:
+			verifiers = append(verifiers, NewAPIKeyVerifier(config.APIKey))
+		case TypeMTLS:
+			verifiers = append(verifiers, NewMTLSVerifier(config.MTLS))
+		default:
+			return nil, fmt.Errorf("unknown auth verifier %s", name)
+		}
+	}
+	return Chain(verifiers...), nil
+}
+
+// Skip 判断方法是否不需要认证
+func (config *Config) Skip(method string) bool {
+	for _, pattern := range config.SkipMethods {
+		if pattern == method {
+			return true
+		}
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func fromMetadata(ctx context.Context, key string) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(key)
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	return values[0], true
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	value, ok := fromMetadata(ctx, "authorization")
+	if !ok || len(value) < 7 || !strings.EqualFold(value[:7], "bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(value[7:]), true
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func bearerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestJWTVerifierHS(t *testing.T) {
+	v, err := NewJWTVerifier(&JWTConfig{Secret: "secret", Issuer: "issuer", NameClaim: "app_name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := SignJWT("HS256", "", []byte("secret"), map[string]interface{}{
+		"sub": "user", "app_name": "order", "iss": "issuer", "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	p, err := v.Verify(bearerContext(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != TypeJWT || p.Name != "order" || p.Subject != "user" {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+
+	expired, _ := SignJWT("HS256", "", []byte("secret"), map[string]interface{}{"iss": "issuer", "exp": time.Now().Add(-time.Minute).Unix()})
+	forged, _ := SignJWT("HS256", "", []byte("other"), map[string]interface{}{"iss": "issuer"})
+	issuer, _ := SignJWT("HS256", "", []byte("secret"), map[string]interface{}{"iss": "other"})
+	noExp, _ := SignJWT("HS256", "", []byte("secret"), map[string]interface{}{"iss": "issuer"})
+	for _, token := range []string{expired, forged, issuer, noExp, "a.b.c"} {
+		if _, err := v.Verify(bearerContext(token)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("token %s: err = %v", token, err)
+		}
+	}
+	if _, err := v.Verify(context.Background()); err != ErrNoCredentials {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 显式配置后接受没有 exp 的 token
+	v.config.AllowNoExp = true
+	if _, err := v.Verify(bearerContext(noExp)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWTVerifierJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "jwks.json")
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"rsa-1","n":"%s","e":"%s"},{"kty":"oct","kid":"hs-1","k":"%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		base64.RawURLEncoding.EncodeToString([]byte("jwks-secret")),
+	)
+	if err := ioutil.WriteFile(file, []byte(jwks), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewJWTVerifier(&JWTConfig{JWKSFile: file, Audience: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+	rs, _ := SignJWT("RS256", "rsa-1", key, map[string]interface{}{"sub": "order", "aud": []string{"user"}, "exp": exp})
+	if p, err := v.Verify(bearerContext(rs)); err != nil || p.Name != "order" {
+		t.Fatalf("principal %+v err %v", p, err)
+	}
+	hs, _ := SignJWT("HS512", "hs-1", []byte("jwks-secret"), map[string]interface{}{"sub": "order", "aud": "user", "exp": exp})
+	if _, err := v.Verify(bearerContext(hs)); err != nil {
+		t.Fatal(err)
+	}
+	unknown, _ := SignJWT("RS256", "rsa-2", key, map[string]interface{}{"sub": "order", "aud": "user", "exp": exp})
+	if _, err := v.Verify(bearerContext(unknown)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 密钥轮换后，检查间隔内不重新读取文件
+	rotated := strings.Replace(jwks, `"rsa-1"`, `"rsa-2"`, 1)
+	if err := ioutil.WriteFile(file, []byte(rotated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(bearerContext(unknown)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v", err)
+	}
+	atomic.StoreInt64(&v.checked, 0)
+	if _, err := v.Verify(bearerContext(unknown)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAPIKeyVerifier(t *testing.T) {
+	v := NewAPIKeyVerifier(&APIKeyConfig{Keys: []APIKey{{Key: "k1", Name: "order"}}})
+	p, err := v.Verify(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k1")))
+	if err != nil || p.Name != "order" || p.Type != TypeAPIKey {
+		t.Fatalf("principal %+v err %v", p, err)
+	}
+	if _, err := v.Verify(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k2"))); err != ErrInvalidCredentials {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestMTLSVerifier(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "order"}, DNSNames: []string{"order.svc"}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+
+	p, err := NewMTLSVerifier(&MTLSConfig{AllowedNames: []string{"order.svc"}}).Verify(ctx)
+	if err != nil || p.Name != "order" || p.Type != TypeMTLS {
+		t.Fatalf("principal %+v err %v", p, err)
+	}
+	if _, err := NewMTLSVerifier(&MTLSConfig{AllowedNames: []string{"user"}}).Verify(ctx); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := NewMTLSVerifier(&MTLSConfig{}).Verify(context.Background()); err != ErrNoCredentials {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	v, err := New(&Config{
+		Verifiers: []string{TypeJWT, TypeAPIKey},
+		JWT:       &JWTConfig{Secret: "secret"},
+		APIKey:    &APIKeyConfig{Keys: []APIKey{{Key: "k1", Name: "order"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := v.Verify(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k1"))); err != nil || p.Type != TypeAPIKey {
+		t.Fatalf("principal %+v err %v", p, err)
+	}
+	if _, err := v.Verify(context.Background()); err != ErrNoCredentials {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 客户端签发的 token 可以通过服务端校验
+	md, err := NewCredentials(&ClientConfig{JWT: &JWTSignerConfig{Secret: "secret"}}).Metadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(md))
+	if p, err := v.Verify(ctx); err != nil || p.Type != TypeJWT {
+		t.Fatalf("principal %+v err %v", p, err)
+	}
+}
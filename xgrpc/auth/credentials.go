@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xapp"
+	"sync"
+	"time"
+)
+
+// Credentials 客户端附加到请求 metadata 中的凭证
+type Credentials interface {
+	Metadata(ctx context.Context) (map[string]string, error)
+}
+
+// BearerToken 固定的 Bearer token
+type BearerToken string
+
+func (t BearerToken) Metadata(context.Context) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// APIKeyCredentials 固定的 API key
+type APIKeyCredentials struct {
+	Header string
+	Key    string
+}
+
+func (c APIKeyCredentials) Metadata(context.Context) (map[string]string, error) {
+	header := c.Header
+	if header == "" {
+		header = DefaultAPIKeyConfig().Header
+	}
+	return map[string]string{header: c.Key}, nil
+}
+
+type JWTSignerConfig struct {
+	Alg      string        `mapStructure:"alg"`    // HS256, HS384, HS512
+	Kid      string        `mapStructure:"kid"`    // 对应服务端 JWKS 中 oct key 的 kid
+	Secret   string        `mapStructure:"secret"` // 签名密钥
+	Issuer   string        `mapStructure:"issuer"`
+	Audience string        `mapStructure:"audience"`
+	TTL      time.Duration `mapStructure:"ttl"` // token 有效期，过半后重新签发
+}
+
+// JWTSigner 以当前应用名签发短期 JWT
+type JWTSigner struct {
+	config  *JWTSignerConfig
+	mu      sync.Mutex
+	token   string
+	refresh time.Time
+}
+
+func NewJWTSigner(config *JWTSignerConfig) *JWTSigner {
+	if config.Alg == "" {
+		config.Alg = "HS256"
+	}
+	if config.TTL <= 0 {
+		config.TTL = 10 * time.Minute
+	}
+	return &JWTSigner{config: config}
+}
+
+func (s *JWTSigner) Metadata(context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.token == "" || now.After(s.refresh) {
+		claims := map[string]interface{}{
+			"sub":      xapp.Name(),
+			"app_name": xapp.Name(),
+			"iat":      now.Unix(),
+			"exp":      now.Add(s.config.TTL).Unix(),
+		}
+		if s.config.Issuer != "" {
+			claims["iss"] = s.config.Issuer
+		}
+		if s.config.Audience != "" {
+			claims["aud"] = s.config.Audience
+		}
+		token, err := SignJWT(s.config.Alg, s.config.Kid, []byte(s.config.Secret), claims)
+		if err != nil {
+			return nil, err
+		}
+		s.token, s.refresh = token, now.Add(s.config.TTL/2)
+	}
+	return map[string]string{"authorization": "Bearer " + s.token}, nil
+}
+
+type ClientConfig struct {
+	Token        string           `mapStructure:"token"`         // 固定的 Bearer token
+	APIKey       string           `mapStructure:"apikey"`        // 固定的 API key
+	APIKeyHeader string           `mapStructure:"apikey_header"` // 默认 x-api-key
+	JWT          *JWTSignerConfig `mapStructure:"jwt"`           // 配置 secret 后自动签发 JWT
+}
+
+// NewCredentials 按配置创建客户端凭证，优先级: jwt > token > apikey，都未配置时返回 nil
+func NewCredentials(config *ClientConfig) Credentials {
+	if config == nil {
+		return nil
+	}
+	switch {
+	case config.JWT != nil && config.JWT.Secret != "":
+		return NewJWTSigner(config.JWT)
+	case config.Token != "":
+		return BearerToken(config.Token)
+	case config.APIKey != "":
+		return APIKeyCredentials{Header: config.APIKeyHeader, Key: config.APIKey}
+	}
+	return nil
+}
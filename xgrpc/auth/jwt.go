@@ -0,0 +1,341 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// jwksCheckInterval 两次检查 JWKS 文件是否变化的最小间隔
+var jwksCheckInterval = 5 * time.Second
+
+type JWTConfig struct {
+	Secret     string        `mapStructure:"secret"`       // HS256/HS384/HS512 使用的密钥
+	JWKSFile   string        `mapStructure:"jwks_file"`    // JWKS 文件，支持 RSA 和 oct 类型的 key，文件变化后按需重新加载
+	Issuer     string        `mapStructure:"issuer"`       // 不为空时校验 iss
+	Audience   string        `mapStructure:"audience"`     // 不为空时校验 aud
+	Leeway     time.Duration `mapStructure:"leeway"`       // 校验 exp, nbf 时允许的时钟偏差
+	NameClaim  string        `mapStructure:"name_claim"`   // 作为调用方名称的 claim，不存在时使用 sub
+	AllowNoExp bool          `mapStructure:"allow_no_exp"` // 是否接受没有 exp 的 token，默认拒绝
+}
+
+func DefaultJWTConfig() *JWTConfig {
+	return &JWTConfig{
+		Leeway:    5 * time.Second,
+		NameClaim: "app_name",
+	}
+}
+
+var hashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// JWTVerifier 校验 authorization: Bearer <token> 中的 JWT
+type JWTVerifier struct {
+	config *JWTConfig
+
+	mu      sync.RWMutex
+	rsaKeys map[string]*rsa.PublicKey
+	hmacKey map[string][]byte
+	modTime time.Time
+	checked int64 // 上次检查 JWKS 文件的时间，UnixNano
+}
+
+func NewJWTVerifier(config *JWTConfig) (*JWTVerifier, error) {
+	if config == nil {
+		config = DefaultJWTConfig()
+	}
+	if config.Secret == "" && config.JWKSFile == "" {
+		return nil, errors.New("jwt verifier requires secret or jwks_file")
+	}
+	v := &JWTVerifier{config: config}
+	if config.JWKSFile != "" {
+		if err := v.loadJWKS(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (v *JWTVerifier) Verify(ctx context.Context) (*Principal, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	claims, err := v.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	name := sub
+	if n, ok := claims[v.config.NameClaim].(string); ok && n != "" {
+		name = n
+	}
+	return &Principal{Type: TypeJWT, Name: name, Subject: sub, Claims: claims}, nil
+}
+
+// Parse 校验签名和标准 claims，返回所有 claims
+func (v *JWTVerifier) Parse(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidCredentials)
+	}
+	if err := v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+	claims := make(map[string]interface{})
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *JWTVerifier) verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	hash, ok := hashes[header.Alg]
+	if !ok {
+		return fmt.Errorf("%w: unsupported alg %s", ErrInvalidCredentials, header.Alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	if strings.HasPrefix(header.Alg, "HS") {
+		key, ok := v.hmacSecret(header.Kid)
+		if !ok {
+			return fmt.Errorf("%w: unknown hmac key %s", ErrInvalidCredentials, header.Kid)
+		}
+		mac := hmac.New(hash.New, key)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidCredentials)
+		}
+		return nil
+	}
+
+	key, ok := v.rsaKey(header.Kid)
+	if !ok {
+		return fmt.Errorf("%w: unknown rsa key %s", ErrInvalidCredentials, header.Kid)
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidCredentials)
+	}
+	return nil
+}
+
+func (v *JWTVerifier) validateClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok && !v.config.AllowNoExp {
+		return fmt.Errorf("%w: missing exp", ErrInvalidCredentials)
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(v.config.Leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidCredentials)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidCredentials)
+	}
+	if v.config.Issuer != "" && claims["iss"] != v.config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidCredentials)
+	}
+	if v.config.Audience != "" && !hasAudience(claims["aud"], v.config.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidCredentials)
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []interface{}:
+		for _, item := range a {
+			if item == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *JWTVerifier) hmacSecret(kid string) ([]byte, bool) {
+	if kid == "" && v.config.Secret != "" {
+		return []byte(v.config.Secret), true
+	}
+	v.reloadIfChanged()
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.hmacKey[kid]
+	if !ok && v.config.Secret != "" {
+		return []byte(v.config.Secret), true
+	}
+	return key, ok
+}
+
+func (v *JWTVerifier) rsaKey(kid string) (*rsa.PublicKey, bool) {
+	v.reloadIfChanged()
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if kid == "" && len(v.rsaKeys) == 1 {
+		for _, key := range v.rsaKeys {
+			return key, true
+		}
+	}
+	key, ok := v.rsaKeys[kid]
+	return key, ok
+}
+
+// reloadIfChanged JWKS 文件修改后重新加载，用于密钥轮换，每 jwksCheckInterval 最多检查一次
+func (v *JWTVerifier) reloadIfChanged() {
+	if v.config.JWKSFile == "" {
+		return
+	}
+	now := time.Now().UnixNano()
+	checked := atomic.LoadInt64(&v.checked)
+	if now-checked < int64(jwksCheckInterval) || !atomic.CompareAndSwapInt64(&v.checked, checked, now) {
+		return
+	}
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil {
+		return
+	}
+	v.mu.RLock()
+	changed := info.ModTime().After(v.modTime)
+	v.mu.RUnlock()
+	if changed {
+		_ = v.loadJWKS()
+	}
+}
+
+func (v *JWTVerifier) loadJWKS() error {
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(v.config.JWKSFile)
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return fmt.Errorf("parse jwks %s: %w", v.config.JWKSFile, err)
+	}
+	rsaKeys := make(map[string]*rsa.PublicKey)
+	hmacKeys := make(map[string][]byte)
+	for _, k := range set.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return fmt.Errorf("jwks key %s: %w", k.Kid, err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return fmt.Errorf("jwks key %s: %w", k.Kid, err)
+			}
+			rsaKeys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return fmt.Errorf("jwks key %s: %w", k.Kid, err)
+			}
+			hmacKeys[k.Kid] = secret
+		}
+	}
+	v.mu.Lock()
+	v.rsaKeys, v.hmacKey, v.modTime = rsaKeys, hmacKeys, info.ModTime()
+	v.mu.Unlock()
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+	return nil
+}
+
+// SignJWT 生成 JWT，key 为 HS 算法的 []byte 密钥或 RS 算法的 *rsa.PrivateKey
+func SignJWT(alg, kid string, key interface{}, claims map[string]interface{}) (string, error) {
+	hash, ok := hashes[alg]
+	if !ok {
+		return "", fmt.Errorf("unsupported alg %s", alg)
+	}
+	header, err := json.Marshal(jwtHeader{Alg: alg, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		h := hash.New()
+		h.Write([]byte(signingInput))
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, h.Sum(nil)); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
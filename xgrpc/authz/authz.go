@@ -0,0 +1,203 @@
+package authz
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xlog"
+	"path"
+	"reflect"
+	"sync"
+)
+
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Rule 授权规则，Callers/Methods 为空表示匹配所有，支持 * 和 /pkg.Service/* 形式的通配
+// Metadata 中的每一项都需要匹配请求 metadata 的取值，取值同样支持通配
+type Rule struct {
+	Effect   string            `mapStructure:"effect"`   // allow, deny
+	Callers  []string          `mapStructure:"callers"`  // 调用方 app_name
+	Methods  []string          `mapStructure:"methods"`  // gRPC FullMethod
+	Metadata map[string]string `mapStructure:"metadata"` // 请求 metadata
+}
+
+type Config struct {
+	Default string `mapStructure:"default"` // 没有规则匹配时的结果，默认 deny
+	Rules   []Rule `mapStructure:"rules"`
+}
+
+// Validate 检查 default 和每条规则的 effect 只能是 allow 或 deny，避免拼写错误时放行请求
+func (c *Config) Validate() error {
+	if c.Default != "" && !validEffect(c.Default) {
+		return fmt.Errorf("invalid default %q, want allow or deny", c.Default)
+	}
+	for i, rule := range c.Rules {
+		if !validEffect(rule.Effect) {
+			return fmt.Errorf("invalid effect %q in rule %d, want allow or deny", rule.Effect, i)
+		}
+	}
+	return nil
+}
+
+func validEffect(effect string) bool {
+	return effect == Allow || effect == Deny
+}
+
+// Request 需要授权的请求
+type Request struct {
+	Caller   string            `json:"caller"`
+	Method   string            `json:"method"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Decision 授权结果，Rule 为命中规则的下标，没有命中时为 -1
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Rule    int    `json:"rule"`
+	Reason  string `json:"reason"`
+}
+
+// match 没有调用方身份的请求只匹配不限制 Callers 的规则
+func (r Rule) match(req Request) bool {
+	if len(r.Callers) > 0 && (req.Caller == "" || !matchAny(r.Callers, req.Caller)) {
+		return false
+	}
+	if len(r.Methods) > 0 && !matchAny(r.Methods, req.Method) {
+		return false
+	}
+	for k, pattern := range r.Metadata {
+		v, ok := req.Metadata[k]
+		if !ok || !match(pattern, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func match(pattern, value string) bool {
+	if pattern == "*" || pattern == value {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Engine 授权引擎，deny 规则优先于 allow 规则
+type Engine struct {
+	name   string
+	key    string
+	mu     sync.RWMutex
+	config *Config
+}
+
+// New 读取 key 下的授权配置，并在 xcfg.OnChange 时热更新，同名的引擎可以在 /debug/authz 中试算
+// 配置不合法时启动失败，热更新时保留原有规则
+func New(key string) *Engine {
+	e := &Engine{name: key, key: key}
+	config, err := e.loadConfig()
+	if err != nil {
+		xlog.Panic("Application Starting",
+			xlog.FieldComponentName("XAuthz"),
+			xlog.FieldMethod("XAuthz.New"),
+			xlog.FieldName(key),
+			xlog.FieldErr(err),
+		)
+	}
+	e.config = config
+	xcfg.OnChange(func(*xcfg.Configuration) {
+		e.Reload()
+	})
+	register(e)
+	return e
+}
+
+// NewWithConfig 使用固定规则创建，不跟随配置变化
+func NewWithConfig(name string, config *Config) *Engine {
+	if err := config.Validate(); err != nil {
+		xlog.Panic("Application Starting",
+			xlog.FieldComponentName("XAuthz"),
+			xlog.FieldMethod("XAuthz.NewWithConfig"),
+			xlog.FieldName(name),
+			xlog.FieldErr(err),
+		)
+	}
+	e := &Engine{name: name, config: config}
+	register(e)
+	return e
+}
+
+func (e *Engine) loadConfig() (*Config, error) {
+	config := xcfg.UnmarshalWithExpect(e.key, &Config{Default: Deny}).(*Config)
+	if config.Default == "" {
+		config.Default = Deny
+	}
+	return config, config.Validate()
+}
+
+func (e *Engine) Reload() {
+	config, err := e.loadConfig()
+	if err != nil {
+		xlog.Error("Application Reload",
+			xlog.FieldComponentName("XAuthz"),
+			xlog.FieldMethod("XAuthz.Reload"),
+			xlog.FieldName(e.name),
+			xlog.FieldDescription("Invalid authz config, keep previous rules"),
+			xlog.FieldErr(err),
+		)
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if reflect.DeepEqual(config, e.config) {
+		return
+	}
+	e.config = config
+	xlog.Info("Application Reload",
+		xlog.FieldComponentName("XAuthz"),
+		xlog.FieldMethod("XAuthz.Reload"),
+		xlog.FieldDescription(fmt.Sprintf("Authz rules reload :%d", len(config.Rules))),
+	)
+}
+
+func (e *Engine) Name() string {
+	return e.name
+}
+
+func (e *Engine) Evaluate(req Request) Decision {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	allowed := -1
+	for i, rule := range e.config.Rules {
+		if !rule.match(req) {
+			continue
+		}
+		if rule.Effect != Allow {
+			return Decision{Allowed: false, Rule: i, Reason: fmt.Sprintf("denied by rule %d", i)}
+		}
+		if allowed < 0 {
+			allowed = i
+		}
+	}
+	if allowed >= 0 {
+		return Decision{Allowed: true, Rule: allowed, Reason: fmt.Sprintf("allowed by rule %d", allowed)}
+	}
+	return Decision{Allowed: e.config.Default == Allow, Rule: -1, Reason: "default " + e.defaultEffect()}
+}
+
+func (e *Engine) defaultEffect() string {
+	if e.config.Default == Allow {
+		return Allow
+	}
+	return Deny
+}
@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xjson"
+	"github.com/coder2z/g-server/xgovern"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	e := NewWithConfig("test", &Config{
+		Default: Deny,
+		Rules: []Rule{
+			{Effect: Allow, Callers: []string{"order", "gateway"}, Methods: []string{"/user.User/*"}},
+			{Effect: Deny, Callers: []string{"gateway"}, Methods: []string{"/user.User/Delete*"}},
+			{Effect: Allow, Methods: []string{"/user.Admin/*"}, Metadata: map[string]string{"x-env": "test*"}},
+		},
+	})
+	cases := []struct {
+		req  Request
+		want bool
+	}{
+		{Request{Caller: "order", Method: "/user.User/Get"}, true},
+		{Request{Caller: "order", Method: "/user.User/DeleteUser"}, true},
+		{Request{Caller: "gateway", Method: "/user.User/DeleteUser"}, false},
+		{Request{Caller: "unknown", Method: "/user.User/Get"}, false},
+		{Request{Caller: "unknown", Method: "/user.Admin/Reset", Metadata: map[string]string{"x-env": "testing"}}, true},
+		{Request{Caller: "unknown", Method: "/user.Admin/Reset", Metadata: map[string]string{"x-env": "prod"}}, false},
+		{Request{Method: "/user.User/Get"}, false},
+		{Request{Method: "/user.Admin/Reset", Metadata: map[string]string{"x-env": "testing"}}, true},
+	}
+	for _, c := range cases {
+		if d := e.Evaluate(c.req); d.Allowed != c.want {
+			t.Errorf("Evaluate(%+v) = %+v, want %v", c.req, d, c.want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, c := range []*Config{
+		{Default: "Deny"},
+		{Default: "reject"},
+		{Rules: []Rule{{Effect: Allow}, {Effect: "DENY"}}},
+		{Rules: []Rule{{}}},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", c)
+		}
+	}
+	if err := (&Config{Default: Allow, Rules: []Rule{{Effect: Deny}}}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if NewWithConfig("empty-default", &Config{}).Evaluate(Request{Caller: "order"}).Allowed {
+		t.Fatal("empty default should deny")
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	_ = xcfg.Apply(map[string]interface{}{
+		"authz": map[string]interface{}{"default": "deny"},
+	})
+	e := New("authz")
+	if e.Evaluate(Request{Caller: "order", Method: "/user.User/Get"}).Allowed {
+		t.Fatal("want denied")
+	}
+
+	_ = xcfg.Apply(map[string]interface{}{
+		"authz": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"effect": "allow", "callers": []interface{}{"order"}}},
+		},
+	})
+	e.Reload()
+	if !e.Evaluate(Request{Caller: "order", Method: "/user.User/Get"}).Allowed {
+		t.Fatal("want allowed after reload")
+	}
+
+	// 拼写错误的配置不生效，保留原有规则
+	_ = xcfg.Apply(map[string]interface{}{
+		"authz": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"effect": "DENY", "callers": []interface{}{"order"}}},
+		},
+	})
+	e.Reload()
+	if !e.Evaluate(Request{Caller: "order", Method: "/user.User/Get"}).Allowed {
+		t.Fatal("invalid config should be refused")
+	}
+
+	w := httptest.NewRecorder()
+	xgovern.HandleFuncs["/debug/authz"](w, httptest.NewRequest("GET", "/debug/authz?name=authz&caller=order&method=/user.User/Get", nil))
+	var res []DryRun
+	if err := xjson.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Name != "authz" || !res[0].Decision.Allowed || res[0].Decision.Rule != 0 {
+		t.Fatalf("unexpected dry run result %s", w.Body.String())
+	}
+}
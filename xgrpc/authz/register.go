@@ -0,0 +1,50 @@
+package authz
+
+import (
+	"github.com/coder2z/g-saber/xjson"
+	"github.com/coder2z/g-server/xgovern"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var engines sync.Map
+
+// DryRun /debug/authz 返回的试算结果
+type DryRun struct {
+	Name     string   `json:"name"`
+	Caller   string   `json:"caller"`
+	Method   string   `json:"method"`
+	Decision Decision `json:"decision"`
+}
+
+func init() {
+	// 试算授权结果: /debug/authz?name=app.grpc.authz&caller=order&method=/user.User/Get&md.x-env=prod
+	xgovern.HandleFunc("/debug/authz", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := Request{
+			Caller:   query.Get("caller"),
+			Method:   query.Get("method"),
+			Metadata: make(map[string]string),
+		}
+		for k, v := range query {
+			if strings.HasPrefix(k, "md.") && len(v) > 0 {
+				req.Metadata[strings.TrimPrefix(k, "md.")] = v[0]
+			}
+		}
+		name := query.Get("name")
+		res := make([]DryRun, 0)
+		engines.Range(func(key, value interface{}) bool {
+			if name == "" || name == key.(string) {
+				res = append(res, DryRun{Name: key.(string), Caller: req.Caller, Method: req.Method, Decision: value.(*Engine).Evaluate(req)})
+			}
+			return true
+		})
+		w.WriteHeader(200)
+		_ = xjson.NewEncoder(w).Encode(res)
+	})
+}
+
+func register(e *Engine) {
+	engines.Store(e.name, e)
+}
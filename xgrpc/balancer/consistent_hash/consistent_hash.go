@@ -64,6 +64,16 @@ func (p *consistentHashPicker) Pick(info balancer.PickInfo) (balancer.PickResult
 	if ok {
 		ret.SubConn = p.subConns[targetAddr]
 	}
+	// hedging 请求需要落到其他节点上，此时放弃一致性选择任一未选中的节点
+	if xbalancer.Excluded(info.Ctx, ret.SubConn) {
+		for _, sc := range p.subConns {
+			if !xbalancer.Excluded(info.Ctx, sc) {
+				ret.SubConn = sc
+				break
+			}
+		}
+	}
+	xbalancer.Picked(info.Ctx, ret.SubConn)
 	return ret, nil
 }
 
@@ -1,6 +1,7 @@
 package least_connection
 
 import (
+	"github.com/coder2z/g-server/xgrpc/balancer"
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/resolver"
@@ -55,24 +56,26 @@ func (p *leastConnectionPicker) Pick(info balancer.PickInfo) (balancer.PickResul
 	if len(p.nodes) == 0 {
 		return ret, balancer.ErrNoSubConnAvailable
 	}
+	nodes := p.candidates(info)
 	var node *node
-	if len(p.nodes) == 1 {
-		node = p.nodes[0]
+	if len(nodes) == 1 {
+		node = nodes[0]
 	} else {
 		p.mu.Lock()
-		a := p.rand.Intn(len(p.nodes))
-		b := p.rand.Intn(len(p.nodes))
+		a := p.rand.Intn(len(nodes))
+		b := p.rand.Intn(len(nodes))
 		p.mu.Unlock()
 		if a == b {
-			b = (b + 1) % len(p.nodes)
+			b = (b + 1) % len(nodes)
 		}
-		if p.nodes[a].inflight < p.nodes[b].inflight {
-			node = p.nodes[a]
+		if atomic.LoadInt64(&nodes[a].inflight) < atomic.LoadInt64(&nodes[b].inflight) {
+			node = nodes[a]
 		} else {
-			node = p.nodes[b]
+			node = nodes[b]
 		}
 	}
 	atomic.AddInt64(&node.inflight, 1)
+	xbalancer.Picked(info.Ctx, node.conn)
 
 	ret.SubConn = node.conn
 	ret.Done = func(info balancer.DoneInfo) {
@@ -80,3 +83,20 @@ func (p *leastConnectionPicker) Pick(info balancer.PickInfo) (balancer.PickResul
 	}
 	return ret, nil
 }
+
+// candidates 排除同一次调用中其他尝试已选中的节点，全部选中过时返回所有节点
+func (p *leastConnectionPicker) candidates(info balancer.PickInfo) []*node {
+	if xbalancer.PickTrackerFromContext(info.Ctx) == nil {
+		return p.nodes
+	}
+	nodes := make([]*node, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		if !xbalancer.Excluded(info.Ctx, n.conn) {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		return p.nodes
+	}
+	return nodes
+}
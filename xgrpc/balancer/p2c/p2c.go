@@ -71,23 +71,24 @@ func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 		chosen *subConn
 		ret    balancer.PickResult
 	)
-	switch len(p.conn) {
+	conn := p.candidates(info)
+	switch len(conn) {
 	case 0:
 		return ret, balancer.ErrNoSubConnAvailable
 	case 1:
-		chosen = p.choose(p.conn[0], nil)
+		chosen = p.choose(conn[0], nil)
 	case 2:
-		chosen = p.choose(p.conn[0], p.conn[1])
+		chosen = p.choose(conn[0], conn[1])
 	default:
 		var node1, node2 *subConn
 		for i := 0; i < pickTimes; i++ {
-			a := p.r.Intn(len(p.conn))
-			b := p.r.Intn(len(p.conn) - 1)
+			a := p.r.Intn(len(conn))
+			b := p.r.Intn(len(conn) - 1)
 			if b >= a {
 				b++
 			}
-			node1 = p.conn[a]
-			node2 = p.conn[b]
+			node1 = conn[a]
+			node2 = conn[b]
 			if node1.healthy() && node2.healthy() {
 				break
 			}
@@ -98,11 +99,29 @@ func (p *p2cPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 
 	atomic.AddInt64(&chosen.inflight, 1)
 	atomic.AddInt64(&chosen.requests, 1)
+	xbalancer.Picked(info.Ctx, chosen.conn)
 	ret.SubConn = chosen.conn
 	ret.Done = p.buildDoneFunc(chosen)
 	return ret, nil
 }
 
+// candidates 排除同一次调用中其他尝试已选中的节点，全部选中过时返回所有节点
+func (p *p2cPicker) candidates(info balancer.PickInfo) []*subConn {
+	if xbalancer.PickTrackerFromContext(info.Ctx) == nil {
+		return p.conn
+	}
+	conn := make([]*subConn, 0, len(p.conn))
+	for _, c := range p.conn {
+		if !xbalancer.Excluded(info.Ctx, c.conn) {
+			conn = append(conn, c)
+		}
+	}
+	if len(conn) == 0 {
+		return p.conn
+	}
+	return conn
+}
+
 func (p *p2cPicker) buildDoneFunc(c *subConn) func(info balancer.DoneInfo) {
 	start := xtime.Now().Unix()
 	return func(info balancer.DoneInfo) {
@@ -49,7 +49,16 @@ type randomPicker struct {
 func (p *randomPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	ret := balancer.PickResult{}
 	p.mu.Lock()
-	ret.SubConn = p.subConns[p.rand.Intn(len(p.subConns))]
+	start := p.rand.Intn(len(p.subConns))
 	p.mu.Unlock()
+	// 跳过同一次调用中其他尝试已选中的节点，全部选中过时使用随机到的节点
+	ret.SubConn = p.subConns[start]
+	for i := 0; i < len(p.subConns); i++ {
+		if sc := p.subConns[(start+i)%len(p.subConns)]; !xbalancer.Excluded(info.Ctx, sc) {
+			ret.SubConn = sc
+			break
+		}
+	}
+	xbalancer.Picked(info.Ctx, ret.SubConn)
 	return ret, nil
 }
@@ -45,11 +45,18 @@ type roundRobinPicker struct {
 	next     int
 }
 
-func (p *roundRobinPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+func (p *roundRobinPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
 	ret := balancer.PickResult{}
 	p.mu.Lock()
-	ret.SubConn = p.subConns[p.next]
-	p.next = (p.next + 1) % len(p.subConns)
+	// 跳过同一次调用中其他尝试已选中的节点，全部选中过时使用当前节点
+	for i := 0; i < len(p.subConns); i++ {
+		ret.SubConn = p.subConns[p.next]
+		p.next = (p.next + 1) % len(p.subConns)
+		if !xbalancer.Excluded(info.Ctx, ret.SubConn) {
+			break
+		}
+	}
 	p.mu.Unlock()
+	xbalancer.Picked(info.Ctx, ret.SubConn)
 	return ret, nil
 }
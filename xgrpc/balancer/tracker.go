@@ -0,0 +1,62 @@
+package xbalancer
+
+import (
+	"context"
+	"google.golang.org/grpc/balancer"
+	"sync"
+)
+
+type trackerKey struct{}
+
+// PickTracker 记录同一次调用的多个尝试(如 hedging)已经选中的 subconn，
+// picker 会尽量避开这些 subconn，使后续尝试落到不同的节点上
+type PickTracker struct {
+	mu    sync.Mutex
+	conns map[balancer.SubConn]struct{}
+}
+
+// WithPickTracker 在 ctx 中放入一个新的 PickTracker，已存在时直接返回
+func WithPickTracker(ctx context.Context) context.Context {
+	if PickTrackerFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, trackerKey{}, &PickTracker{conns: make(map[balancer.SubConn]struct{})})
+}
+
+func PickTrackerFromContext(ctx context.Context) *PickTracker {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(trackerKey{}).(*PickTracker)
+	return t
+}
+
+// Len 已选中的 subconn 数量
+func (t *PickTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.conns)
+}
+
+// Excluded 判断 subconn 是否已被本次调用的其他尝试选中
+func Excluded(ctx context.Context, sc balancer.SubConn) bool {
+	t := PickTrackerFromContext(ctx)
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.conns[sc]
+	return ok
+}
+
+// Picked 记录本次调用选中的 subconn
+func Picked(ctx context.Context, sc balancer.SubConn) {
+	t := PickTrackerFromContext(ctx)
+	if t == nil || sc == nil {
+		return
+	}
+	t.mu.Lock()
+	t.conns[sc] = struct{}{}
+	t.mu.Unlock()
+}
@@ -0,0 +1,281 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/coder2z/g-server/xcode"
+	"google.golang.org/grpc/codes"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	SREAlgorithm   = "sre"
+	StateAlgorithm = "state"
+)
+
+type State int32
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrNotAllowed 熔断器拒绝请求
+var ErrNotAllowed = errors.New("circuit breaker is open")
+
+type Breaker interface {
+	Allow() error
+	MarkSuccess()
+	MarkFailed()
+	State() State
+}
+
+type Config struct {
+	Algorithm        string        `mapStructure:"algorithm"`          // sre: 自适应限流, state: 关闭/打开/半开状态机
+	Window           time.Duration `mapStructure:"window"`             // 统计窗口
+	Buckets          int           `mapStructure:"buckets"`            // 窗口切分的桶数
+	MinRequests      int64         `mapStructure:"min_requests"`       // 窗口内请求数低于该值时不熔断
+	K                float64       `mapStructure:"k"`                  // sre: 请求数超过 K 倍成功数时开始按概率拒绝
+	ErrorRatio       float64       `mapStructure:"error_ratio"`        // state: 错误率超过该值时打开
+	SleepWindow      time.Duration `mapStructure:"sleep_window"`       // state: 打开后经过该时间进入半开
+	HalfOpenRequests int64         `mapStructure:"half_open_requests"` // state: 半开状态下允许的探测请求数
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Algorithm:        SREAlgorithm,
+		Window:           10 * time.Second,
+		Buckets:          40,
+		MinRequests:      20,
+		K:                1.5,
+		ErrorRatio:       0.5,
+		SleepWindow:      5 * time.Second,
+		HalfOpenRequests: 3,
+	}
+}
+
+// merge 返回副本，零值字段使用 base 中的值
+func (c *Config) merge(base *Config) *Config {
+	res := *c
+	if res.Algorithm == "" {
+		res.Algorithm = base.Algorithm
+	}
+	if res.Window <= 0 {
+		res.Window = base.Window
+	}
+	if res.Buckets <= 0 {
+		res.Buckets = base.Buckets
+	}
+	if res.MinRequests <= 0 {
+		res.MinRequests = base.MinRequests
+	}
+	if res.K <= 0 {
+		res.K = base.K
+	}
+	if res.ErrorRatio <= 0 {
+		res.ErrorRatio = base.ErrorRatio
+	}
+	if res.SleepWindow <= 0 {
+		res.SleepWindow = base.SleepWindow
+	}
+	if res.HalfOpenRequests <= 0 {
+		res.HalfOpenRequests = base.HalfOpenRequests
+	}
+	return &res
+}
+
+// New 按配置创建熔断器，未配置的字段使用 DefaultConfig，onChange 在状态变化时调用
+func New(config *Config, onChange func(from, to State)) Breaker {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config = config.merge(DefaultConfig())
+	if config.Algorithm == StateAlgorithm {
+		return newStateBreaker(config, onChange)
+	}
+	return newSREBreaker(config, onChange)
+}
+
+// Acceptable 判断错误是否计入熔断统计，业务错误码和调用方错误不会触发熔断
+func Acceptable(err error) bool {
+	if err == nil {
+		return true
+	}
+	code := xcode.ExtractCodes(err).GetCodeAsUint32()
+	if code > xcode.CodeBreakUp {
+		return true
+	}
+	switch codes.Code(code) {
+	case codes.Unknown, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return false
+	default:
+		return true
+	}
+}
+
+// sreBreaker Google SRE 自适应限流: 拒绝概率 = max(0, (requests - K * accepts) / (requests + 1))
+type sreBreaker struct {
+	mu       sync.Mutex
+	config   *Config
+	stat     *stat
+	r        *rand.Rand
+	state    State
+	onChange func(from, to State)
+}
+
+func newSREBreaker(config *Config, onChange func(from, to State)) *sreBreaker {
+	return &sreBreaker{
+		config:   config,
+		stat:     newStat(config.Window, config.Buckets),
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		onChange: onChange,
+	}
+}
+
+func (b *sreBreaker) Allow() error {
+	accepts, total := b.stat.sum()
+	requests := b.config.K * float64(accepts)
+	if total < b.config.MinRequests || float64(total) < requests {
+		b.setState(StateClosed)
+		return nil
+	}
+	b.setState(StateOpen)
+	ratio := math.Max(0, (float64(total)-requests)/float64(total+1))
+	b.mu.Lock()
+	drop := b.r.Float64() < ratio
+	b.mu.Unlock()
+	if drop {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+func (b *sreBreaker) MarkSuccess() {
+	b.stat.add(1)
+}
+
+func (b *sreBreaker) MarkFailed() {
+	b.stat.add(0)
+}
+
+func (b *sreBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *sreBreaker) setState(to State) {
+	b.mu.Lock()
+	from := b.state
+	b.state = to
+	b.mu.Unlock()
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
+
+// stateBreaker 经典熔断状态机
+// closed: 错误率超过阈值后打开; open: 经过 SleepWindow 后半开; half-open: 探测请求全部成功后关闭，任一失败重新打开
+type stateBreaker struct {
+	mu       sync.Mutex
+	config   *Config
+	stat     *stat
+	state    State
+	openedAt time.Time
+	probes   int64
+	passed   int64
+	onChange func(from, to State)
+}
+
+func newStateBreaker(config *Config, onChange func(from, to State)) *stateBreaker {
+	return &stateBreaker{
+		config:   config,
+		stat:     newStat(config.Window, config.Buckets),
+		onChange: onChange,
+	}
+}
+
+func (b *stateBreaker) Allow() error {
+	b.mu.Lock()
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.config.SleepWindow {
+			b.mu.Unlock()
+			return ErrNotAllowed
+		}
+		b.transition(StateHalfOpen)
+		fallthrough
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			b.mu.Unlock()
+			return ErrNotAllowed
+		}
+		b.probes++
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *stateBreaker) MarkSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		b.passed++
+		if b.passed >= b.config.HalfOpenRequests {
+			b.transition(StateClosed)
+		}
+		return
+	}
+	b.stat.add(1)
+}
+
+func (b *stateBreaker) MarkFailed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		b.transition(StateOpen)
+		return
+	}
+	b.stat.add(0)
+	accepts, total := b.stat.sum()
+	if b.state == StateClosed && total >= b.config.MinRequests &&
+		float64(total-accepts)/float64(total) >= b.config.ErrorRatio {
+		b.transition(StateOpen)
+	}
+}
+
+func (b *stateBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// transition 调用方需持有锁
+func (b *stateBreaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.probes, b.passed = 0, 0
+	switch to {
+	case StateOpen:
+		b.openedAt = time.Now()
+	case StateClosed:
+		b.stat.reset()
+	}
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,190 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/coder2z/g-server/xcode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestStateBreaker(t *testing.T) {
+	var transitions []string
+	b := New(&Config{
+		Algorithm:        StateAlgorithm,
+		Window:           time.Second,
+		Buckets:          10,
+		MinRequests:      4,
+		ErrorRatio:       0.5,
+		SleepWindow:      50 * time.Millisecond,
+		HalfOpenRequests: 2,
+	}, func(from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	b.MarkSuccess()
+	b.MarkSuccess()
+	b.MarkFailed()
+	if b.State() != StateClosed {
+		t.Fatalf("want closed, got %s", b.State())
+	}
+	b.MarkFailed()
+	if b.State() != StateOpen {
+		t.Fatalf("want open, got %s", b.State())
+	}
+	if err := b.Allow(); err != ErrNotAllowed {
+		t.Fatalf("want ErrNotAllowed, got %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Allow(); err != ErrNotAllowed {
+		t.Fatalf("half-open should only allow %d probes", 2)
+	}
+	b.MarkSuccess()
+	b.MarkSuccess()
+	if b.State() != StateClosed {
+		t.Fatalf("want closed, got %s", b.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("want %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, transitions)
+		}
+	}
+}
+
+func TestSREBreaker(t *testing.T) {
+	b := New(&Config{
+		Window:      time.Second,
+		Buckets:     10,
+		MinRequests: 10,
+		K:           1.5,
+	}, nil)
+
+	for i := 0; i < 100; i++ {
+		b.MarkFailed()
+	}
+	dropped := 0
+	for i := 0; i < 100; i++ {
+		if b.Allow() == ErrNotAllowed {
+			dropped++
+		}
+	}
+	if dropped == 0 || b.State() != StateOpen {
+		t.Fatalf("sre breaker should drop requests, dropped %d state %s", dropped, b.State())
+	}
+
+	b = New(&Config{Window: time.Second, Buckets: 10, MinRequests: 10, K: 1.5}, nil)
+	for i := 0; i < 100; i++ {
+		b.MarkSuccess()
+	}
+	for i := 0; i < 100; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAcceptable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, true},
+		{status.Error(codes.Unavailable, "unavailable"), false},
+		{status.Error(codes.InvalidArgument, "invalid argument"), true},
+		{xcode.BusinessCodeAdd(xcode.CodeBreakUp+14, "business"), true},
+		{errors.New("unknown"), false},
+	}
+	for _, c := range cases {
+		if got := Acceptable(c.err); got != c.want {
+			t.Errorf("Acceptable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(&GroupConfig{
+		Default: &Config{Algorithm: StateAlgorithm, Window: time.Second, Buckets: 10, MinRequests: 1, ErrorRatio: 0.5, SleepWindow: time.Minute},
+	})
+	failed := status.Error(codes.Unavailable, "unavailable")
+	if err := g.Do("target", "/pkg.Svc/Method", func() error { return failed }); err != failed {
+		t.Fatalf("want %v, got %v", failed, err)
+	}
+	if err := g.Do("target", "/pkg.Svc/Method", func() error { return nil }); err != ErrNotAllowed {
+		t.Fatalf("want ErrNotAllowed, got %v", err)
+	}
+	if err := g.Do("target", "/pkg.Svc/Other", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// target 和 method 拼接相同时不共用熔断器
+	if err := g.Do("target/pkg.Svc", "/Method", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewGroup(nil)
+	other.Get("target", "/pkg.Svc/Method")
+	if n := len(g.Stats()); n != 3 {
+		t.Fatalf("group entries = %d", n)
+	}
+	if n := len(Stats()); n != 4 {
+		t.Fatalf("entries = %d", n)
+	}
+	other.Close()
+	g.Close()
+	if n := len(Stats()); n != 0 {
+		t.Fatalf("entries after close = %d", n)
+	}
+}
+
+func TestNewGroupKeepsConfig(t *testing.T) {
+	config := &GroupConfig{}
+	g := NewGroup(config)
+	defer g.Close()
+	if config.Default != nil {
+		t.Fatal("NewGroup should not modify the passed config")
+	}
+	if g.config.Default == nil {
+		t.Fatal("group should fall back to the default config")
+	}
+}
+
+func TestPartialConfig(t *testing.T) {
+	b := New(&Config{MinRequests: 5}, nil)
+	for i := 0; i < 1000; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("request %d dropped while all calls succeed", i)
+		}
+		b.MarkSuccess()
+	}
+
+	// method 只覆盖 MinRequests，其余字段沿用 Default
+	g := NewGroup(&GroupConfig{
+		Default: &Config{Algorithm: StateAlgorithm, Window: time.Second, Buckets: 10, MinRequests: 100, ErrorRatio: 0.5, SleepWindow: 20 * time.Millisecond, HalfOpenRequests: 1},
+		Methods: map[string]*Config{"/pkg.Svc/Method": {MinRequests: 1}},
+	})
+	defer g.Close()
+	failed := status.Error(codes.Unavailable, "unavailable")
+	_ = g.Do("target", "/pkg.Svc/Method", func() error { return failed })
+	if s := g.Get("target", "/pkg.Svc/Method").State(); s != StateOpen {
+		t.Fatalf("want open, got %s", s)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := g.Do("target", "/pkg.Svc/Method", func() error { return nil }); err != nil {
+		t.Fatalf("half-open probe rejected: %v", err)
+	}
+	if s := g.Get("target", "/pkg.Svc/Method").State(); s != StateClosed {
+		t.Fatalf("want closed, got %s", s)
+	}
+}
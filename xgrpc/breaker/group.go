@@ -0,0 +1,128 @@
+package breaker
+
+import (
+	"github.com/coder2z/g-saber/xjson"
+	"github.com/coder2z/g-server/xgovern"
+	"github.com/coder2z/g-server/xmonitor"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// GroupConfig 默认配置，以及按 FullMethod 覆盖的配置
+type GroupConfig struct {
+	Default *Config            `mapStructure:"default"`
+	Methods map[string]*Config `mapStructure:"methods"`
+}
+
+func DefaultGroupConfig() *GroupConfig {
+	return &GroupConfig{
+		Default: DefaultConfig(),
+	}
+}
+
+// Group 按 target 和 method 分别维护熔断器，不再使用时调用 Close
+type Group struct {
+	config   *GroupConfig
+	breakers sync.Map // key -> Breaker
+}
+
+type key struct {
+	target string
+	method string
+}
+
+// Entry 熔断器状态
+type Entry struct {
+	Target string `json:"target"`
+	Method string `json:"method"`
+	State  string `json:"state"`
+}
+
+// groups /debug/breaker 展示的 Group
+var groups sync.Map
+
+func init() {
+	xgovern.HandleFunc("/debug/breaker", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_ = xjson.NewEncoder(w).Encode(Stats())
+	})
+}
+
+// Stats 所有未关闭的 Group 中熔断器的当前状态
+func Stats() []Entry {
+	res := make([]Entry, 0)
+	groups.Range(func(g, _ interface{}) bool {
+		res = append(res, g.(*Group).Stats()...)
+		return true
+	})
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Target != res[j].Target {
+			return res[i].Target < res[j].Target
+		}
+		return res[i].Method < res[j].Method
+	})
+	return res
+}
+
+// NewGroup 使用 config 的副本补全默认值，config 可能同时被客户端重建和 /debug 接口使用
+func NewGroup(config *GroupConfig) *Group {
+	if config == nil {
+		config = DefaultGroupConfig()
+	}
+	c := *config
+	if c.Default == nil {
+		c.Default = DefaultConfig()
+	}
+	g := &Group{config: &c}
+	groups.Store(g, struct{}{})
+	return g
+}
+
+// Stats 当前 Group 中熔断器的状态
+func (g *Group) Stats() []Entry {
+	res := make([]Entry, 0)
+	g.breakers.Range(func(k, b interface{}) bool {
+		res = append(res, Entry{Target: k.(key).target, Method: k.(key).method, State: b.(Breaker).State().String()})
+		return true
+	})
+	return res
+}
+
+// Close 从 /debug/breaker 中移除，连接或客户端被替换、关闭时调用
+func (g *Group) Close() {
+	groups.Delete(g)
+}
+
+// Get 获取 target 下 method 对应的熔断器，method 的配置中未设置的字段使用 Default
+func (g *Group) Get(target, method string) Breaker {
+	k := key{target: target, method: method}
+	if b, ok := g.breakers.Load(k); ok {
+		return b.(Breaker)
+	}
+	config := g.config.Default
+	if c, ok := g.config.Methods[method]; ok && c != nil {
+		config = c.merge(config)
+	}
+	b := New(config, func(from, to State) {
+		xmonitor.ClientBreakerStateGauge.WithLabelValues(target, method).Set(float64(to))
+		xmonitor.ClientBreakerTransitionCounter.WithLabelValues(target, method, from.String(), to.String()).Inc()
+	})
+	actual, _ := g.breakers.LoadOrStore(k, b)
+	return actual.(Breaker)
+}
+
+// Do 使用熔断器执行 fn，根据 Acceptable 的结果记录成功或失败
+func (g *Group) Do(target, method string, fn func() error) error {
+	b := g.Get(target, method)
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	if Acceptable(err) {
+		b.MarkSuccess()
+	} else {
+		b.MarkFailed()
+	}
+	return err
+}
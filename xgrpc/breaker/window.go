@@ -0,0 +1,42 @@
+package breaker
+
+import (
+	"github.com/coder2z/g-server/xgrpc/internal/window"
+	"sync"
+	"time"
+)
+
+const (
+	fieldAccepts = iota
+	fieldTotal
+)
+
+// stat 记录窗口内每个桶的成功数和请求数
+type stat struct {
+	mu sync.Mutex
+	w  *window.Window
+}
+
+func newStat(length time.Duration, n int) *stat {
+	return &stat{w: window.New(length, n, 2)}
+}
+
+func (s *stat) add(accept int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.w.Current()
+	b[fieldAccepts] += accept
+	b[fieldTotal]++
+}
+
+func (s *stat) sum() (accepts int64, total int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Sum(fieldAccepts), s.w.Sum(fieldTotal)
+}
+
+func (s *stat) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Reset()
+}
@@ -0,0 +1,40 @@
+package clientinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func withCredentials(ctx context.Context, creds auth.Credentials) (context.Context, error) {
+	kv, err := creds.Metadata(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	for k, v := range kv {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+	return ctx, nil
+}
+
+// CredentialsUnaryClientInterceptor 把凭证附加到请求的 metadata 中
+func CredentialsUnaryClientInterceptor(creds auth.Credentials) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := withCredentials(ctx, creds)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func CredentialsStreamClientInterceptor(creds auth.Credentials) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := withCredentials(ctx, creds)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,28 @@
+package clientinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"google.golang.org/grpc"
+)
+
+// XDeadlineUnaryClientInterceptor ctx 没有 deadline 时按方法设置默认超时，并把剩余预算写入 metadata 传给下游
+func XDeadlineUnaryClientInterceptor(timeouts deadline.Timeouts) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			if timeout := timeouts.Lookup(method); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+		}
+		return invoker(deadline.Inject(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// XDeadlineStreamClientInterceptor 只传递已有的剩余预算，不为流设置默认超时
+func XDeadlineStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(deadline.Inject(ctx), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,108 @@
+package clientinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/balancer"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"github.com/coder2z/g-server/xmonitor"
+	"github.com/coder2z/g-server/xtrace"
+	"github.com/golang/protobuf/proto"
+	"github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"reflect"
+	"time"
+)
+
+type hedgeResult struct {
+	reply proto.Message
+	err   error
+	hedge bool
+}
+
+// HedgingUnaryClientInterceptor 对启用 hedging 的方法，首次请求超过延迟(或 p99)未返回时向其他节点发出相同的请求，
+// 使用最先成功的响应并取消其他请求。hedge 请求数受 target 的预算限制
+func HedgingUnaryClientInterceptor(name string, hedger *retry.Hedger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		out, ok := reply.(proto.Message)
+		if !ok || !hedger.Enabled(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		budget := hedger.Budget(cc.Target())
+		budget.Deposit()
+
+		ctx, cancel := context.WithCancel(xbalancer.WithPickTracker(ctx))
+		defer cancel()
+
+		results := make(chan hedgeResult, hedger.MaxHedges()+1)
+		send := func(hedge bool) {
+			r := reflect.New(reflect.TypeOf(out).Elem()).Interface().(proto.Message)
+			go func() {
+				err := invoker(ctx, method, req, r, cc, opts...)
+				results <- hedgeResult{reply: r, err: err, hedge: hedge}
+			}()
+		}
+
+		var (
+			start    = time.Now()
+			inflight = 1
+			hedges   = 0
+			timer    <-chan time.Time
+			lastErr  error
+		)
+		send(false)
+		delay, ok := hedger.Delay(method)
+		if ok {
+			t := time.NewTimer(delay)
+			defer t.Stop()
+			timer = t.C
+		}
+		span := xtrace.SpanFromContext(ctx)
+
+		for inflight > 0 {
+			select {
+			case res := <-results:
+				inflight--
+				if res.err != nil {
+					lastErr = res.err
+					continue
+				}
+				hedger.Observe(method, time.Since(start))
+				out.Reset()
+				proto.Merge(out, res.reply)
+				if res.hedge {
+					xmonitor.ClientHedgeCounter.WithLabelValues(xmonitor.TypeGRPCUnary, name, method, cc.Target(), "won").Inc()
+				}
+				if span != nil {
+					span.SetTag("grpc.hedges", hedges)
+					span.SetTag("grpc.hedge_won", res.hedge)
+				}
+				return nil
+			case <-timer:
+				timer = nil
+				if hedges >= hedger.MaxHedges() {
+					continue
+				}
+				if !budget.Withdraw() {
+					xmonitor.ClientHedgeCounter.WithLabelValues(xmonitor.TypeGRPCUnary, name, method, cc.Target(), "budget exhausted").Inc()
+					continue
+				}
+				hedges++
+				inflight++
+				send(true)
+				xmonitor.ClientHedgeCounter.WithLabelValues(xmonitor.TypeGRPCUnary, name, method, cc.Target(), "sent").Inc()
+				if span != nil {
+					span.LogFields(log.String("event", "hedge"), log.Int("hedge", hedges), log.String("delay", delay.String()))
+				}
+				if hedges < hedger.MaxHedges() {
+					t := time.NewTimer(delay)
+					defer t.Stop()
+					timer = t.C
+				}
+			}
+		}
+		if span != nil {
+			span.SetTag("grpc.hedges", hedges)
+		}
+		return lastErr
+	}
+}
@@ -0,0 +1,98 @@
+package clientinterceptors
+
+import (
+	"context"
+	"fmt"
+	"github.com/coder2z/g-server/xgrpc/balancer/random"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/examples/helloworld/helloworld"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"net"
+	"testing"
+	"time"
+)
+
+type greeter struct {
+	name  string
+	delay time.Duration
+}
+
+func (g *greeter) SayHello(ctx context.Context, in *helloworld.HelloRequest) (*helloworld.HelloReply, error) {
+	select {
+	case <-time.After(g.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &helloworld.HelloReply{Message: g.name}, nil
+}
+
+func startGreeter(t *testing.T, g *greeter) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	helloworld.RegisterGreeterServer(s, g)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestHedgingUnaryClientInterceptor(t *testing.T) {
+	slow := startGreeter(t, &greeter{name: "slow", delay: time.Second})
+	fast := startGreeter(t, &greeter{name: "fast"})
+
+	r := manual.NewBuilderWithScheme("hedging")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: slow}, {Addr: fast}}})
+
+	hedger, err := retry.NewHedger(&retry.HedgingConfig{
+		Delay:     50 * time.Millisecond,
+		MaxHedges: 1,
+		Methods:   []string{"/helloworld.Greeter/*"},
+		Budget:    &retry.BudgetConfig{Ratio: 1, MinPerSecond: 100, Window: time.Second, Buckets: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.Dial(r.Scheme()+":///greeter",
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"LoadBalancingPolicy": "%s"}`, random.Random)),
+		grpc.WithUnaryInterceptor(HedgingUnaryClientInterceptor("test", hedger)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := helloworld.NewGreeterClient(conn)
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+		resp, err := client.SayHello(context.Background(), &helloworld.HelloRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// hedge 请求必须落到另一个节点上
+		if resp.Message != "fast" || time.Since(start) > 500*time.Millisecond {
+			t.Fatalf("reply from %s after %s", resp.Message, time.Since(start))
+		}
+	}
+}
+
+func TestHedgerDelay(t *testing.T) {
+	hedger, _ := retry.NewHedger(&retry.HedgingConfig{UseP99: true, Methods: []string{retry.Any}})
+	if _, ok := hedger.Delay("/pkg.Svc/Get"); ok {
+		t.Fatal("no delay without samples")
+	}
+	for i := 1; i <= 100; i++ {
+		hedger.Observe("/pkg.Svc/Get", time.Duration(i)*time.Millisecond)
+	}
+	if d, ok := hedger.Delay("/pkg.Svc/Get"); !ok || d != 99*time.Millisecond {
+		t.Fatalf("delay = %s", d)
+	}
+}
@@ -3,17 +3,19 @@ package clientinterceptors
 import (
 	"context"
 	"errors"
-	"github.com/afex/hystrix-go/hystrix"
 	"github.com/coder2z/g-saber/xcast"
 	"github.com/coder2z/g-saber/xlog"
 	"github.com/coder2z/g-saber/xstring"
 	"github.com/coder2z/g-server/xapp"
 	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/breaker"
+	"github.com/coder2z/g-server/xlogger"
 	"github.com/coder2z/g-server/xmonitor"
 	"github.com/coder2z/g-server/xtrace"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"time"
@@ -113,6 +115,7 @@ func XTimeoutUnaryClientInterceptor(timeout time.Duration, slowThreshold time.Du
 	}
 }
 
+// XLoggerUnaryClientInterceptor 记录系统错误和业务错误，默认包含请求和响应内容，可通过 xgovern /debug/log/payload 临时关闭
 func XLoggerUnaryClientInterceptor(name string) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		beg := time.Now()
@@ -120,34 +123,24 @@ func XLoggerUnaryClientInterceptor(name string) grpc.UnaryClientInterceptor {
 
 		spbStatus := xcode.ExtractCodes(err)
 		if err != nil {
+			fields := []xlog.Field{
+				xlog.FieldType("client"),
+				xlog.FieldType("unary"),
+				xlog.FieldCode(spbStatus.Code),
+				xlog.FieldErrKind(spbStatus.Message),
+				xlog.FieldName(name),
+				xlog.FieldMethod(method),
+				xlog.FieldCost(time.Since(beg)),
+			}
+			if enabled, ok := xlogger.Payload(); enabled || !ok {
+				fields = append(fields, xlog.Any("req", req), xlog.Any("reply", reply))
+			}
 			// 只记录系统级别错误
 			if spbStatus.Code < xcast.ToInt32(xcode.CodeBreakUp) {
-				xlog.Error(
-					"GRPC Server Internal Error",
-					xlog.FieldType("client"),
-					xlog.FieldType("unary"),
-					xlog.FieldCode(spbStatus.Code),
-					xlog.FieldErrKind(spbStatus.Message),
-					xlog.FieldName(name),
-					xlog.FieldMethod(method),
-					xlog.FieldCost(time.Since(beg)),
-					xlog.Any("req", req),
-					xlog.Any("reply", reply),
-				)
+				xlog.Error("GRPC Server Internal Error", fields...)
 				err = spbStatus.SetMsg("server internal error") //吃掉内部错误
 			} else {
-				xlog.Warn(
-					"GRPC Business Error",
-					xlog.FieldType("client"),
-					xlog.FieldType("unary"),
-					xlog.FieldCode(spbStatus.Code),
-					xlog.FieldErrKind(spbStatus.Message),
-					xlog.FieldName(name),
-					xlog.FieldMethod(method),
-					xlog.FieldCost(time.Since(beg)),
-					xlog.Any("req", req),
-					xlog.Any("reply", reply),
-				)
+				xlog.Warn("GRPC Business Error", fields...)
 			}
 			return err
 		}
@@ -162,76 +155,61 @@ type XAid struct {
 	HostIP   string `json:"host_ip"`
 }
 
+func aidContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	var info = XAid{
+		AppName:  xapp.Name(),
+		HostName: xapp.HostName(),
+		AppId:    xapp.AppId(),
+		HostIP:   xapp.HostIP(),
+	}
+	clientAidMD := metadata.Pairs(
+		"info", xstring.Json(info),
+		"ip", xapp.HostIP(),
+		"app_id", xapp.AppId(),
+		"app_name", xapp.Name(),
+		"host_name", xapp.HostName(),
+	)
+	if ok {
+		md = metadata.Join(md, clientAidMD)
+	} else {
+		md = clientAidMD
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
 func XAidUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		md, ok := metadata.FromOutgoingContext(ctx)
-		var info = XAid{
-			AppName:  xapp.Name(),
-			HostName: xapp.HostName(),
-			AppId:    xapp.AppId(),
-			HostIP:   xapp.HostIP(),
-		}
-		clientAidMD := metadata.Pairs(
-			"info", xstring.Json(info),
-			"ip", xapp.HostIP(),
-			"app_id", xapp.AppId(),
-			"app_name", xapp.Name(),
-			"host_name", xapp.HostName(),
-		)
-		if ok {
-			md = metadata.Join(md, clientAidMD)
-		} else {
-			md = clientAidMD
-		}
-		ctx = metadata.NewOutgoingContext(ctx, md)
-		return invoker(ctx, method, req, reply, cc, opts...)
+		return invoker(aidContext(ctx), method, req, reply, cc, opts...)
 	}
 }
 
-func HystrixUnaryClientIntercept(timeout, maxConcurrentRequests, requestVolumeThreshold, errorPercentThreshold, sleepWindow int,
-	fallback func(context.Context, error) error) grpc.UnaryClientInterceptor {
-	//		Timeout：				1000	// 超时时间设置  单位毫秒
-	//		MaxConcurrentRequests:  1,		// 最大请求数
-	//		RequestVolumeThreshold: 2,		// 默认20，如果错误超过该次数，才开始计算错误百分比
-	//		ErrorPercentThreshold:  50, 	// 错误百分比，默认50，即50%
-	//		SleepWindow:            5000, 	// 过多长时间，熔断器再次检测是否开启。单位毫秒
-	config := hystrix.CommandConfig{
-		Timeout:                timeout,
-		MaxConcurrentRequests:  maxConcurrentRequests,
-		RequestVolumeThreshold: requestVolumeThreshold,
-		ErrorPercentThreshold:  errorPercentThreshold,
-		SleepWindow:            sleepWindow,
-	}
+// ErrBreakerOpen 熔断器拒绝请求时返回的系统错误码
+var ErrBreakerOpen = xcode.SystemCodeAdd(uint32(codes.Unavailable), "circuit breaker is open")
+
+// BreakerUnaryClientInterceptor 按 target 和 method 熔断，业务错误码不计入失败
+func BreakerUnaryClientInterceptor(group *breaker.Group) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		name := "GUC" + method
-		hystrix.ConfigureCommand(name, config)
-		return hystrix.DoC(ctx, name, func(ctx context.Context) error {
+		err := group.Do(cc.Target(), method, func() error {
 			return invoker(ctx, method, req, reply, cc, opts...)
-		}, fallback)
+		})
+		if err == breaker.ErrNotAllowed {
+			return ErrBreakerOpen
+		}
+		return err
 	}
 }
 
-func HystrixStreamClientInterceptor(timeout, maxConcurrentRequests, requestVolumeThreshold, errorPercentThreshold, sleepWindow int,
-	fallback func(context.Context, error) error) grpc.StreamClientInterceptor {
-	//		Timeout：				1000	// 超时时间设置  单位毫秒
-	//		MaxConcurrentRequests:  1,		// 最大请求数
-	//		RequestVolumeThreshold: 2,		// 默认20，如果错误超过该次数，才开始计算错误百分比
-	//		ErrorPercentThreshold:  50, 	// 错误百分比，默认50，即50%
-	//		SleepWindow:            5000, 	// 过多长时间，熔断器再次检测是否开启。单位毫秒
-	config := hystrix.CommandConfig{
-		Timeout:                timeout,
-		MaxConcurrentRequests:  maxConcurrentRequests,
-		RequestVolumeThreshold: requestVolumeThreshold,
-		ErrorPercentThreshold:  errorPercentThreshold,
-		SleepWindow:            sleepWindow,
-	}
+// BreakerStreamClientInterceptor 只统计建立流的结果
+func BreakerStreamClientInterceptor(group *breaker.Group) grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (cs grpc.ClientStream, err error) {
-		name := "GSC" + method
-		hystrix.ConfigureCommand(name, config)
-		err = hystrix.DoC(ctx, name, func(ctx context.Context) error {
+		err = group.Do(cc.Target(), method, func() error {
 			cs, err = streamer(ctx, desc, cc, method, opts...)
 			return err
-		}, fallback)
-		return
+		})
+		if err == breaker.ErrNotAllowed {
+			return nil, ErrBreakerOpen
+		}
+		return cs, err
 	}
 }
@@ -0,0 +1,72 @@
+package clientinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-saber/xcast"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"github.com/coder2z/g-server/xmonitor"
+	"github.com/coder2z/g-server/xtrace"
+	"github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"time"
+)
+
+// RetryUnaryClientInterceptor 对白名单中的幂等方法按策略重试
+// 等待时间超过剩余 deadline 或 target 的重试预算不足时不再重试，返回最后一次的错误
+func RetryUnaryClientInterceptor(name string, policy *retry.Policy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !policy.Idempotent(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		budget := policy.Budget(cc.Target())
+		budget.Deposit()
+
+		var (
+			err     error
+			attempt int
+		)
+		for {
+			attempt++
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= policy.MaxAttempts() {
+				break
+			}
+			code := xcode.ExtractCodes(err).GetCodeAsUint32()
+			if code > xcode.CodeBreakUp || !policy.Retryable(codes.Code(code)) {
+				break
+			}
+			delay := policy.Backoff(attempt)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+				break
+			}
+			if !budget.Withdraw() {
+				xmonitor.ClientRetryCounter.WithLabelValues(xmonitor.TypeGRPCUnary, name, method, cc.Target(), "budget exhausted").Inc()
+				break
+			}
+			xmonitor.ClientRetryCounter.WithLabelValues(xmonitor.TypeGRPCUnary, name, method, cc.Target(), xcast.ToString(code)).Inc()
+			if span := xtrace.SpanFromContext(ctx); span != nil {
+				span.LogFields(log.String("event", "retry"), log.Int("attempt", attempt+1), log.String("message", err.Error()))
+			}
+			if !sleep(ctx, delay) {
+				break
+			}
+		}
+		if span := xtrace.SpanFromContext(ctx); span != nil {
+			span.SetTag("grpc.attempts", attempt)
+		}
+		return err
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package clientinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestRetryUnaryClientInterceptor(t *testing.T) {
+	policy, err := retry.New(&retry.Config{
+		MaxAttempts: 3,
+		Codes:       []string{"UNAVAILABLE"},
+		Methods:     []string{"/pkg.User/Get"},
+		BaseDelay:   time.Millisecond,
+		Multiplier:  2,
+		Budget:      retry.DefaultBudgetConfig(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := grpc.Dial("passthrough:///127.0.0.1:0", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	interceptor := RetryUnaryClientInterceptor("test", policy)
+	call := func(method string, errs ...error) (int, error) {
+		attempts := 0
+		err := interceptor(context.Background(), method, nil, nil, cc,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				err := errs[attempts]
+				attempts++
+				return err
+			})
+		return attempts, err
+	}
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	if n, err := call("/pkg.User/Get", unavailable, unavailable, nil); n != 3 || err != nil {
+		t.Fatalf("attempts=%d err=%v", n, err)
+	}
+	if n, err := call("/pkg.User/Get", unavailable, unavailable, unavailable); n != 3 || err != unavailable {
+		t.Fatalf("attempts=%d err=%v", n, err)
+	}
+	internal := status.Error(codes.Internal, "internal")
+	if n, err := call("/pkg.User/Get", internal); n != 1 || err != internal {
+		t.Fatalf("attempts=%d err=%v", n, err)
+	}
+	// 不在幂等白名单中的方法不重试
+	if n, err := call("/pkg.User/Create", unavailable); n != 1 || err != unavailable {
+		t.Fatalf("attempts=%d err=%v", n, err)
+	}
+}
+
+func TestRetryHonorsDeadline(t *testing.T) {
+	policy, _ := retry.New(&retry.Config{
+		MaxAttempts: 5,
+		Codes:       []string{"UNAVAILABLE"},
+		Methods:     []string{retry.Any},
+		BaseDelay:   time.Second,
+		Multiplier:  1,
+	})
+	cc, err := grpc.Dial("passthrough:///127.0.0.1:0", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	_ = RetryUnaryClientInterceptor("test", policy)(ctx, "/pkg.User/Get", nil, nil, cc,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			attempts++
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, backoff exceeds deadline", attempts)
+	}
+}
@@ -0,0 +1,223 @@
+package clientinterceptors
+
+import (
+	"context"
+	"errors"
+	"github.com/coder2z/g-saber/xcast"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xtrace"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	messageSend = "send"
+	messageRecv = "recv"
+)
+
+// monitoredClientStream 记录收发消息数和单条消息耗时，流真正结束时只回调一次 onFinish
+type monitoredClientStream struct {
+	grpc.ClientStream
+	desc      *grpc.StreamDesc
+	sent      int64
+	recv      int64
+	once      sync.Once
+	done      chan struct{}
+	onMessage func(kind string, cost time.Duration)
+	onFinish  func(err error, sent, recv int64)
+}
+
+func newMonitoredClientStream(ctx context.Context, cs grpc.ClientStream, desc *grpc.StreamDesc,
+	onMessage func(kind string, cost time.Duration), onFinish func(err error, sent, recv int64)) *monitoredClientStream {
+	s := &monitoredClientStream{
+		ClientStream: cs,
+		desc:         desc,
+		done:         make(chan struct{}),
+		onMessage:    onMessage,
+		onFinish:     onFinish,
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.finish(ctx.Err())
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+func (s *monitoredClientStream) SendMsg(m interface{}) error {
+	beg := time.Now()
+	err := s.ClientStream.SendMsg(m)
+	s.message(messageSend, time.Since(beg))
+	if err == nil {
+		atomic.AddInt64(&s.sent, 1)
+	} else if err != io.EOF {
+		// io.EOF 表示服务端已结束，真正的状态由 RecvMsg 返回
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *monitoredClientStream) RecvMsg(m interface{}) error {
+	beg := time.Now()
+	err := s.ClientStream.RecvMsg(m)
+	s.message(messageRecv, time.Since(beg))
+	switch {
+	case err == nil:
+		atomic.AddInt64(&s.recv, 1)
+		if !s.desc.ServerStreams {
+			s.finish(nil)
+		}
+	case err == io.EOF:
+		s.finish(nil)
+	default:
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *monitoredClientStream) CloseSend() error {
+	err := s.ClientStream.CloseSend()
+	if err != nil {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *monitoredClientStream) message(kind string, cost time.Duration) {
+	if s.onMessage != nil {
+		s.onMessage(kind, cost)
+	}
+}
+
+func (s *monitoredClientStream) finish(err error) {
+	s.once.Do(func() {
+		close(s.done)
+		if s.onFinish != nil {
+			s.onFinish(err, atomic.LoadInt64(&s.sent), atomic.LoadInt64(&s.recv))
+		}
+	})
+}
+
+func XTraceStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if !ok {
+			md = metadata.New(nil)
+		} else {
+			md = md.Copy()
+		}
+
+		span, ctx := xtrace.StartSpanFromContext(
+			ctx,
+			method,
+			xtrace.TagSpanKind("client.stream"),
+			xtrace.TagComponent("grpc"),
+			xtrace.CustomTag("isServerStream", desc.ServerStreams),
+			xtrace.CustomTag("isClientStream", desc.ClientStreams),
+		)
+
+		finish := func(err error, sent, recv int64) {
+			span.SetTag("sent_messages", sent)
+			span.SetTag("recv_messages", recv)
+			if err != nil {
+				spbStatus := xcode.ExtractCodes(err)
+				span.SetTag("response_code", spbStatus.GetCode())
+				ext.Error.Set(span, true)
+				span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+			}
+			span.Finish()
+		}
+
+		ctx = xtrace.MetadataInjector(ctx, md)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			finish(err, 0, 0)
+			return nil, err
+		}
+		return newMonitoredClientStream(ctx, cs, desc, nil, finish), nil
+	}
+}
+
+func XTimeoutStreamClientInterceptor(timeout time.Duration, slowThreshold time.Duration) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cancel := context.CancelFunc(func() {})
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		onMessage := func(kind string, cost time.Duration) {
+			if slowThreshold > time.Duration(0) && cost > slowThreshold {
+				xlog.Error("GRPC Timeout Error",
+					xlog.FieldErr(errors.New("GRPC Stream Message Timeout command")),
+					xlog.FieldMethod(method),
+					xlog.FieldComponentName("GRPC"),
+					xlog.FieldName(cc.Target()),
+					xlog.FieldCost(cost),
+					xlog.FieldType("Client"),
+					xlog.FieldKind(kind),
+				)
+			}
+		}
+		// 流结束后才释放 timeout context
+		return newMonitoredClientStream(ctx, cs, desc, onMessage, func(error, int64, int64) {
+			cancel()
+		}), nil
+	}
+}
+
+func XLoggerStreamClientInterceptor(name string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		beg := time.Now()
+		logError := func(err error, sent, recv int64) {
+			if err == nil {
+				return
+			}
+			spbStatus := xcode.ExtractCodes(err)
+			fields := []xlog.Field{
+				xlog.FieldType("client"),
+				xlog.FieldType("stream"),
+				xlog.FieldCode(spbStatus.Code),
+				xlog.FieldErrKind(spbStatus.Message),
+				xlog.FieldName(name),
+				xlog.FieldMethod(method),
+				xlog.FieldCost(time.Since(beg)),
+				xlog.Int64("sent", sent),
+				xlog.Int64("recv", recv),
+			}
+			// 只记录系统级别错误
+			if spbStatus.Code < xcast.ToInt32(xcode.CodeBreakUp) {
+				xlog.Error("GRPC Server Internal Error", fields...)
+			} else {
+				xlog.Warn("GRPC Business Error", fields...)
+			}
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			logError(err, 0, 0)
+			return nil, err
+		}
+		return newMonitoredClientStream(ctx, cs, desc, nil, logError), nil
+	}
+}
+
+func XAidStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(aidContext(ctx), desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,70 @@
+package clientinterceptors
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"io"
+	"testing"
+	"time"
+)
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recv []error
+}
+
+func (f *fakeClientStream) SendMsg(m interface{}) error {
+	return nil
+}
+
+func (f *fakeClientStream) RecvMsg(m interface{}) error {
+	err := f.recv[0]
+	f.recv = f.recv[1:]
+	return err
+}
+
+func TestMonitoredClientStream(t *testing.T) {
+	var (
+		calls    int
+		sent     int64
+		recv     int64
+		finalErr error
+		messages int
+	)
+	cs := newMonitoredClientStream(context.Background(),
+		&fakeClientStream{recv: []error{nil, nil, io.EOF}},
+		&grpc.StreamDesc{ServerStreams: true, ClientStreams: true},
+		func(kind string, cost time.Duration) { messages++ },
+		func(err error, s, r int64) {
+			calls++
+			finalErr, sent, recv = err, s, r
+		},
+	)
+
+	_ = cs.SendMsg(nil)
+	for cs.RecvMsg(nil) == nil {
+	}
+	// 结束后再次调用不会重复回调
+	cs.finish(errors.New("again"))
+
+	if calls != 1 || finalErr != nil || sent != 1 || recv != 2 || messages != 4 {
+		t.Fatalf("calls=%d err=%v sent=%d recv=%d messages=%d", calls, finalErr, sent, recv, messages)
+	}
+}
+
+func TestMonitoredClientStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	newMonitoredClientStream(ctx, &fakeClientStream{}, &grpc.StreamDesc{ServerStreams: true}, nil,
+		func(err error, s, r int64) { done <- err })
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream not finished after context cancel")
+	}
+}
@@ -0,0 +1,99 @@
+package deadline
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"path"
+	"strconv"
+	"time"
+)
+
+// BudgetKey 跨服务传递的剩余超时预算(毫秒)
+const BudgetKey = "x-deadline-budget"
+
+// MethodTimeout 按方法配置的默认超时，Method 支持 * 和 /pkg.Service/* 形式
+type MethodTimeout struct {
+	Method  string        `mapStructure:"method"`
+	Timeout time.Duration `mapStructure:"timeout"`
+}
+
+// Timeouts 客户端默认超时，按 Methods 的顺序匹配，都不匹配时使用 Default
+type Timeouts struct {
+	Default time.Duration
+	Methods []MethodTimeout
+}
+
+func (t Timeouts) Lookup(method string) time.Duration {
+	for _, m := range t.Methods {
+		if m.Method == "*" || m.Method == method {
+			return m.Timeout
+		}
+		if ok, _ := path.Match(m.Method, method); ok {
+			return m.Timeout
+		}
+	}
+	return t.Default
+}
+
+// Inject 把 ctx 的剩余时间写入 outgoing metadata，没有 deadline 时不写入
+func Inject(ctx context.Context) context.Context {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+	left := time.Until(dl)
+	if left < 0 {
+		left = 0
+	}
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.New(nil)
+	}
+	md.Set(BudgetKey, strconv.FormatInt(int64(left/time.Millisecond), 10))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// FromIncomingContext 读取上游传递的剩余预算
+func FromIncomingContext(ctx context.Context) (time.Duration, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	values := md.Get(BudgetKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// Budget 计算本次请求可用的时间：取上游预算和 ctx deadline 中较小者减去 reserve，
+// 上游没有传递预算和 deadline 时才使用本地 timeout，避免覆盖调用方按方法配置的更长超时
+// 上游预算已经耗尽时返回 false
+func Budget(ctx context.Context, timeout, reserve time.Duration) (time.Duration, bool) {
+	var (
+		left  time.Duration
+		found bool
+	)
+	if b, ok := FromIncomingContext(ctx); ok {
+		left, found = b, true
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		if d := time.Until(dl); !found || d < left {
+			left, found = d, true
+		}
+	}
+	if !found {
+		return timeout, true
+	}
+	left -= reserve
+	if left <= 0 {
+		return 0, false
+	}
+	return left, true
+}
@@ -0,0 +1,52 @@
+package deadline
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"testing"
+	"time"
+)
+
+func TestTimeoutsLookup(t *testing.T) {
+	timeouts := Timeouts{
+		Default: time.Second,
+		Methods: []MethodTimeout{
+			{Method: "/user.User/Export*", Timeout: 30 * time.Second},
+			{Method: "/user.User/Get", Timeout: 100 * time.Millisecond},
+		},
+	}
+	for method, want := range map[string]time.Duration{
+		"/user.User/ExportAll": 30 * time.Second,
+		"/user.User/Get":       100 * time.Millisecond,
+		"/user.User/Create":    time.Second,
+	} {
+		if got := timeouts.Lookup(method); got != want {
+			t.Errorf("Lookup(%s) = %s, want %s", method, got, want)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	md, _ := metadata.FromOutgoingContext(Inject(ctx))
+	incoming := metadata.NewIncomingContext(context.Background(), md)
+
+	budget, ok := Budget(incoming, 5*time.Second, 100*time.Millisecond)
+	if !ok || budget > 900*time.Millisecond || budget < 800*time.Millisecond {
+		t.Fatalf("budget = %s, ok = %v", budget, ok)
+	}
+	// 上游预算大于本地 timeout 时以上游为准
+	if budget, ok = Budget(incoming, 200*time.Millisecond, 100*time.Millisecond); !ok || budget < 800*time.Millisecond {
+		t.Fatalf("budget = %s, ok = %v", budget, ok)
+	}
+
+	expired := metadata.NewIncomingContext(context.Background(), metadata.Pairs(BudgetKey, "5"))
+	if _, ok = Budget(expired, time.Second, 10*time.Millisecond); ok {
+		t.Fatal("want budget exhausted")
+	}
+
+	if budget, ok = Budget(context.Background(), time.Second, 10*time.Millisecond); !ok || budget != time.Second {
+		t.Fatalf("budget = %s, ok = %v", budget, ok)
+	}
+}
@@ -0,0 +1,106 @@
+// Package window 熔断、降载、重试预算和限流共用的滚动窗口
+package window
+
+import "time"
+
+// Window 滚动窗口，窗口被切分为多个桶，每个桶保存 fields 个计数，过期的桶在访问时清空；
+// 不是并发安全的，由调用方加锁
+type Window struct {
+	size   time.Duration
+	fields int
+	counts []int64
+	offset int
+	last   time.Time
+}
+
+// New length 为窗口长度，n 为桶数，n 不大于 0 时为 10，桶长度最小为 1ms
+func New(length time.Duration, n, fields int) *Window {
+	if n <= 0 {
+		n = 10
+	}
+	size := length / time.Duration(n)
+	if size <= 0 {
+		size = time.Millisecond
+	}
+	return &Window{
+		size:   size,
+		fields: fields,
+		counts: make([]int64, n*fields),
+		last:   time.Now(),
+	}
+}
+
+// Size 单个桶的时间长度
+func (w *Window) Size() time.Duration {
+	return w.size
+}
+
+func (w *Window) advance() {
+	elapsed := int(time.Since(w.last) / w.size)
+	if elapsed <= 0 {
+		return
+	}
+	n := len(w.counts) / w.fields
+	for i := 0; i < elapsed && i < n; i++ {
+		w.offset = (w.offset + 1) % n
+		w.clear(w.offset)
+	}
+	w.last = w.last.Add(time.Duration(elapsed) * w.size)
+}
+
+func (w *Window) clear(i int) {
+	b := w.counts[i*w.fields : (i+1)*w.fields]
+	for j := range b {
+		b[j] = 0
+	}
+}
+
+// Length 窗口长度
+func (w *Window) Length() time.Duration {
+	return w.size * time.Duration(len(w.counts)/w.fields)
+}
+
+// Start 当前桶的开始时间
+func (w *Window) Start() time.Time {
+	w.advance()
+	return w.last
+}
+
+// Current 当前桶的计数，可以直接修改
+func (w *Window) Current() []int64 {
+	w.advance()
+	return w.counts[w.offset*w.fields : (w.offset+1)*w.fields]
+}
+
+// Add 当前桶的第 field 个计数加 delta
+func (w *Window) Add(field int, delta int64) {
+	w.Current()[field] += delta
+}
+
+// Sum 窗口内第 field 个计数的总和
+func (w *Window) Sum(field int) int64 {
+	w.advance()
+	var sum int64
+	for i := field; i < len(w.counts); i += w.fields {
+		sum += w.counts[i]
+	}
+	return sum
+}
+
+// Completed 依次处理除当前桶以外的桶
+func (w *Window) Completed(fn func(bucket []int64)) {
+	w.advance()
+	for i := 0; i < len(w.counts)/w.fields; i++ {
+		if i != w.offset {
+			fn(w.counts[i*w.fields : (i+1)*w.fields])
+		}
+	}
+}
+
+// Reset 清空所有桶
+func (w *Window) Reset() {
+	for i := range w.counts {
+		w.counts[i] = 0
+	}
+	w.last = time.Now()
+}
@@ -0,0 +1,34 @@
+package window
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	w := New(100*time.Millisecond, 5, 2)
+	w.Add(0, 1)
+	w.Add(1, 3)
+	if w.Sum(0) != 1 || w.Sum(1) != 3 {
+		t.Fatalf("sum = %d, %d", w.Sum(0), w.Sum(1))
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	w.Add(0, 2)
+	var completed int64
+	w.Completed(func(b []int64) { completed += b[0] })
+	if completed != 1 || w.Sum(0) != 3 {
+		t.Fatalf("completed = %d, sum = %d", completed, w.Sum(0))
+	}
+
+	// 超过窗口长度后所有桶过期
+	time.Sleep(120 * time.Millisecond)
+	if w.Sum(0) != 0 || w.Sum(1) != 0 {
+		t.Fatalf("sum after window = %d, %d", w.Sum(0), w.Sum(1))
+	}
+	w.Add(0, 1)
+	w.Reset()
+	if w.Sum(0) != 0 {
+		t.Fatal("reset should clear buckets")
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"github.com/coder2z/g-server/xgrpc/internal/window"
+	"sync"
+	"time"
+)
+
+const (
+	TokenBucketAlgorithm   = "token_bucket"
+	SlidingWindowAlgorithm = "sliding_window"
+)
+
+type Limiter interface {
+	Allow() bool
+}
+
+// reserver 放行时同时返回归还本次配额的函数
+type reserver interface {
+	reserve() (refund func(), ok bool)
+}
+
+// TokenBucket 令牌桶，每秒生成 rate 个令牌，最多积累 burst 个
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *TokenBucket) reserve() (func(), bool) {
+	if !b.Allow() {
+		return nil, false
+	}
+	return b.refund, true
+}
+
+func (b *TokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens++; b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// SlidingWindow 滑动窗口，window 时间内最多通过 limit 个请求，窗口被切分为 buckets 个桶
+type SlidingWindow struct {
+	mu    sync.Mutex
+	limit int64
+	w     *window.Window
+}
+
+func NewSlidingWindow(limit int, length time.Duration, buckets int) *SlidingWindow {
+	return &SlidingWindow{
+		limit: int64(limit),
+		w:     window.New(length, buckets, 1),
+	}
+}
+
+func (w *SlidingWindow) Allow() bool {
+	_, ok := w.reserve()
+	return ok
+}
+
+// reserve 归还时扣减放行时计数的桶，该桶已经被窗口回收时不再扣减
+func (w *SlidingWindow) reserve() (func(), bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.w.Sum(0) >= w.limit {
+		return nil, false
+	}
+	bucket, start := w.w.Current(), w.w.Start()
+	bucket[0]++
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.w.Start().Sub(start) < w.w.Length() && bucket[0] > 0 {
+			bucket[0]--
+		}
+	}, true
+}
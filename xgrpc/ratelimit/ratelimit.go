@@ -0,0 +1,228 @@
+package ratelimit
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xlog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const Any = "*"
+
+// managers 所有跟随配置变化的 Manager，按配置 key 区分
+var managers sync.Map
+
+func init() {
+	xcfg.OnChange(func(*xcfg.Configuration) {
+		managers.Range(func(_, value interface{}) bool {
+			value.(*Manager).Reload()
+			return true
+		})
+	})
+}
+
+// idleTimeout 超过该时间没有请求的限流器会被回收，下次请求时重新创建
+var idleTimeout = 10 * time.Minute
+
+// Rule 限流规则
+// Method/Caller 为空表示该维度不区分，所有请求共享配额；为 * 表示每个取值单独一份配额；其他值表示只匹配该取值
+// Caller 优先为认证得到的调用方名称，没有认证身份时为 metadata 中的 app_name
+type Rule struct {
+	Method    string        `mapStructure:"method"`    // gRPC FullMethod
+	Caller    string        `mapStructure:"caller"`    // 调用方名称
+	Algorithm string        `mapStructure:"algorithm"` // token_bucket, sliding_window
+	Rate      float64       `mapStructure:"rate"`      // token_bucket 每秒生成的令牌数
+	Burst     int           `mapStructure:"burst"`     // token_bucket 桶容量
+	Limit     int           `mapStructure:"limit"`     // sliding_window 窗口内最大请求数
+	Window    time.Duration `mapStructure:"window"`    // sliding_window 窗口大小
+	Buckets   int           `mapStructure:"buckets"`   // sliding_window 窗口切分的桶数
+}
+
+type Config struct {
+	Rules []Rule `mapStructure:"rules"`
+}
+
+// Validate 算法名拼写错误或配额为 0 的规则会拦截所有请求，加载配置时拒绝
+func (c *Config) Validate() error {
+	for i, rule := range c.Rules {
+		switch rule.Algorithm {
+		case "", TokenBucketAlgorithm:
+			if rule.Rate <= 0 {
+				return fmt.Errorf("invalid rate %v in rule %d, want greater than 0", rule.Rate, i)
+			}
+		case SlidingWindowAlgorithm:
+			if rule.Limit <= 0 {
+				return fmt.Errorf("invalid limit %d in rule %d, want greater than 0", rule.Limit, i)
+			}
+			if rule.Window <= 0 {
+				return fmt.Errorf("invalid window %v in rule %d, want greater than 0", rule.Window, i)
+			}
+		default:
+			return fmt.Errorf("invalid algorithm %q in rule %d, want %s or %s", rule.Algorithm, i, TokenBucketAlgorithm, SlidingWindowAlgorithm)
+		}
+	}
+	return nil
+}
+
+func (r Rule) match(method, caller string) bool {
+	return (r.Method == "" || r.Method == Any || r.Method == method) &&
+		(r.Caller == "" || r.Caller == Any || r.Caller == caller)
+}
+
+func (r Rule) key(index int, method, caller string) string {
+	if r.Method == "" {
+		method = ""
+	}
+	if r.Caller == "" {
+		caller = ""
+	}
+	return fmt.Sprintf("%d|%s|%s", index, method, caller)
+}
+
+func (r Rule) newLimiter() Limiter {
+	if r.Algorithm == SlidingWindowAlgorithm {
+		return NewSlidingWindow(r.Limit, r.Window, r.Buckets)
+	}
+	return NewTokenBucket(r.Rate, r.Burst)
+}
+
+type entry struct {
+	limiter Limiter
+	last    int64 // 最近一次使用的时间，UnixNano
+}
+
+// Manager 按规则管理限流器，配置变化时重建
+type Manager struct {
+	key       string
+	mu        sync.RWMutex
+	rules     []Rule
+	limiters  sync.Map
+	lastSweep int64
+}
+
+// New 读取 key 下的限流配置，并在 xcfg.OnChange 时热更新
+// 相同 key 返回同一个 Manager，server 重建时不会重复订阅配置变化，也不会重置已消耗的配额
+// 配置不合法时启动失败，热更新时保留原有规则
+func New(key string) *Manager {
+	if m, ok := managers.Load(key); ok {
+		return m.(*Manager)
+	}
+	m := &Manager{key: key}
+	config, err := m.loadConfig()
+	if err != nil {
+		xlog.Panic("Application Starting",
+			xlog.FieldComponentName("XRateLimit"),
+			xlog.FieldMethod("XRateLimit.New"),
+			xlog.FieldName(key),
+			xlog.FieldErr(err),
+		)
+	}
+	m.rules = config.Rules
+	actual, _ := managers.LoadOrStore(key, m)
+	return actual.(*Manager)
+}
+
+// NewWithConfig 使用固定规则创建，不跟随配置变化
+func NewWithConfig(config *Config) *Manager {
+	if err := config.Validate(); err != nil {
+		xlog.Panic("Application Starting",
+			xlog.FieldComponentName("XRateLimit"),
+			xlog.FieldMethod("XRateLimit.NewWithConfig"),
+			xlog.FieldErr(err),
+		)
+	}
+	return &Manager{rules: config.Rules}
+}
+
+func (m *Manager) loadConfig() (*Config, error) {
+	config := xcfg.UnmarshalWithExpect(m.key, &Config{}).(*Config)
+	return config, config.Validate()
+}
+
+func (m *Manager) Reload() {
+	config, err := m.loadConfig()
+	if err != nil {
+		xlog.Error("Application Reload",
+			xlog.FieldComponentName("XRateLimit"),
+			xlog.FieldMethod("XRateLimit.Reload"),
+			xlog.FieldName(m.key),
+			xlog.FieldDescription("Invalid rate limit config, keep previous rules"),
+			xlog.FieldErr(err),
+		)
+		return
+	}
+	rules := config.Rules
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if reflect.DeepEqual(rules, m.rules) {
+		return
+	}
+	m.rules = rules
+	m.limiters = sync.Map{}
+	xlog.Info("Application Reload",
+		xlog.FieldComponentName("XRateLimit"),
+		xlog.FieldMethod("XRateLimit.Reload"),
+		xlog.FieldDescription(fmt.Sprintf("Rate limit rules reload :%d", len(rules))),
+	)
+}
+
+// Allow 所有匹配的规则都通过才放行，被拒绝时归还前面规则已消耗的配额
+func (m *Manager) Allow(method, caller string) bool {
+	now := time.Now().UnixNano()
+	m.sweep(now)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var refunds []func()
+	for i, rule := range m.rules {
+		if !rule.match(method, caller) {
+			continue
+		}
+		key := rule.key(i, method, caller)
+		e, ok := m.limiters.Load(key)
+		if !ok {
+			e, _ = m.limiters.LoadOrStore(key, &entry{limiter: rule.newLimiter(), last: now})
+		}
+		atomic.StoreInt64(&e.(*entry).last, now)
+		limiter := e.(*entry).limiter
+		r, ok := limiter.(reserver)
+		if !ok {
+			if !limiter.Allow() {
+				refundAll(refunds)
+				return false
+			}
+			continue
+		}
+		refund, allowed := r.reserve()
+		if !allowed {
+			refundAll(refunds)
+			return false
+		}
+		refunds = append(refunds, refund)
+	}
+	return true
+}
+
+func refundAll(refunds []func()) {
+	for _, refund := range refunds {
+		refund()
+	}
+}
+
+// sweep 每隔 idleTimeout 回收一次空闲的限流器，避免调用方或方法取值过多时内存持续增长
+func (m *Manager) sweep(now int64) {
+	last := atomic.LoadInt64(&m.lastSweep)
+	if now-last < int64(idleTimeout) || !atomic.CompareAndSwapInt64(&m.lastSweep, last, now) {
+		return
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.limiters.Range(func(key, value interface{}) bool {
+		if now-atomic.LoadInt64(&value.(*entry).last) >= int64(idleTimeout) {
+			m.limiters.Delete(key)
+		}
+		return true
+	})
+}
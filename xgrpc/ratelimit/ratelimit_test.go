@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"github.com/coder2z/g-saber/xcfg"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(0, 2)
+	if !b.Allow() || !b.Allow() || b.Allow() {
+		t.Fatal("token bucket should allow burst requests only")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	w := NewSlidingWindow(2, 100*time.Millisecond, 10)
+	if !w.Allow() || !w.Allow() || w.Allow() {
+		t.Fatal("sliding window should allow limit requests only")
+	}
+	time.Sleep(120 * time.Millisecond)
+	if !w.Allow() {
+		t.Fatal("sliding window should allow after window passed")
+	}
+}
+
+func TestManager(t *testing.T) {
+	m := NewWithConfig(&Config{Rules: []Rule{
+		{Method: "/user.User/Get", Caller: Any, Algorithm: TokenBucketAlgorithm, Rate: 0.001, Burst: 1},
+		{Caller: "order", Algorithm: SlidingWindowAlgorithm, Limit: 2, Window: time.Minute},
+	}})
+
+	// 每个调用方单独一份配额
+	if !m.Allow("/user.User/Get", "a") || !m.Allow("/user.User/Get", "b") {
+		t.Fatal("each caller should have its own quota")
+	}
+	if m.Allow("/user.User/Get", "a") {
+		t.Fatal("caller a should be limited")
+	}
+
+	// order 在所有方法上共享配额
+	if !m.Allow("/user.User/List", "order") || !m.Allow("/user.User/Update", "order") {
+		t.Fatal("order should be allowed")
+	}
+	if m.Allow("/user.User/Delete", "order") {
+		t.Fatal("order should be limited")
+	}
+	if !m.Allow("/user.User/Delete", "pay") {
+		t.Fatal("pay should not be limited")
+	}
+}
+
+func TestManagerRefund(t *testing.T) {
+	m := NewWithConfig(&Config{Rules: []Rule{
+		{Caller: Any, Algorithm: SlidingWindowAlgorithm, Limit: 2, Window: time.Minute},
+		{Method: "/user.User/Get", Rate: 0.001, Burst: 1},
+	}})
+	if !m.Allow("/user.User/Get", "a") || m.Allow("/user.User/Get", "a") {
+		t.Fatal("second Get should be limited by the method rule")
+	}
+	// 被拒绝的请求不消耗调用方配额
+	if !m.Allow("/user.User/List", "a") || m.Allow("/user.User/List", "a") {
+		t.Fatal("caller a should have exactly one request left")
+	}
+}
+
+func TestManagerSweep(t *testing.T) {
+	defer func(d time.Duration) { idleTimeout = d }(idleTimeout)
+	idleTimeout = 50 * time.Millisecond
+
+	m := NewWithConfig(&Config{Rules: []Rule{{Method: Any, Caller: Any, Rate: 0.001, Burst: 1}}})
+	for _, caller := range []string{"a", "b", "c"} {
+		m.Allow("/user.User/Get", caller)
+	}
+	if n := count(m); n != 3 {
+		t.Fatalf("limiters = %d", n)
+	}
+	time.Sleep(60 * time.Millisecond)
+	m.Allow("/user.User/Get", "d")
+	if n := count(m); n != 1 {
+		t.Fatalf("limiters after sweep = %d", n)
+	}
+}
+
+func count(m *Manager) int {
+	n := 0
+	m.limiters.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func TestSlidingWindowRefund(t *testing.T) {
+	w := NewSlidingWindow(2, time.Second, 10)
+	refund, ok := w.reserve()
+	if !ok {
+		t.Fatal("first request should be allowed")
+	}
+	// 归还时当前桶已经变化，扣减的仍然是放行时计数的桶
+	time.Sleep(120 * time.Millisecond)
+	if !w.Allow() {
+		t.Fatal("second request should be allowed")
+	}
+	refund()
+	if b := w.w.Current(); b[0] != 1 {
+		t.Fatalf("current bucket = %d, want 1", b[0])
+	}
+	if sum := w.w.Sum(0); sum != 1 {
+		t.Fatalf("sum = %d, want 1", sum)
+	}
+}
+
+func TestNewSharesManager(t *testing.T) {
+	if New("app.grpc.ratelimit.test") != New("app.grpc.ratelimit.test") {
+		t.Fatal("same key should return the same manager")
+	}
+}
+
+func TestManagerReload(t *testing.T) {
+	_ = xcfg.Apply(map[string]interface{}{
+		"app": map[string]interface{}{"ratelimit": map[string]interface{}{"reload": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"caller": "order", "rate": 0.001, "burst": 1}},
+		}}},
+	})
+	m := New("app.ratelimit.reload")
+	if !m.Allow("/user.User/Get", "order") || m.Allow("/user.User/Get", "order") {
+		t.Fatal("order should be limited after burst")
+	}
+
+	// 拼写错误的算法不生效，保留原有规则
+	_ = xcfg.Apply(map[string]interface{}{
+		"app": map[string]interface{}{"ratelimit": map[string]interface{}{"reload": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"caller": "pay", "algorithm": "sliding-window", "limit": 1, "window": "1s"}},
+		}}},
+	})
+	m.Reload()
+	if m.Allow("/user.User/Get", "order") || !m.Allow("/user.User/Get", "pay") || !m.Allow("/user.User/Get", "pay") {
+		t.Fatal("invalid config should be refused")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, rule := range []Rule{
+		{Algorithm: "tokenbucket", Rate: 1},
+		{Rate: 0, Burst: 10},
+		{Algorithm: SlidingWindowAlgorithm, Window: time.Second},
+		{Algorithm: SlidingWindowAlgorithm, Limit: 10},
+	} {
+		if err := (&Config{Rules: []Rule{rule}}).Validate(); err == nil {
+			t.Fatalf("rule %+v should be invalid", rule)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewWithConfig should refuse invalid rules")
+		}
+	}()
+	NewWithConfig(&Config{Rules: []Rule{{Algorithm: "sliding-window", Limit: 1, Window: time.Second}}})
+}
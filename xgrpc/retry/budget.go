@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"github.com/coder2z/g-server/xgrpc/internal/window"
+	"sync"
+	"time"
+)
+
+type BudgetConfig struct {
+	Ratio        float64       `mapStructure:"ratio"`          // 窗口内重试数不超过请求数的比例
+	MinPerSecond int           `mapStructure:"min_per_second"` // 请求量很小时每秒仍允许的重试数
+	Window       time.Duration `mapStructure:"window"`         // 统计窗口
+	Buckets      int           `mapStructure:"buckets"`        // 窗口切分的桶数
+}
+
+func DefaultBudgetConfig() *BudgetConfig {
+	return &BudgetConfig{
+		Ratio:        0.1,
+		MinPerSecond: 10,
+		Window:       10 * time.Second,
+		Buckets:      10,
+	}
+}
+
+const (
+	fieldRequests = iota
+	fieldRetries
+)
+
+// Budget 重试预算，窗口内重试数超过 MinPerSecond * Window + Ratio * 请求数 时拒绝重试，避免重试风暴
+type Budget struct {
+	config *BudgetConfig
+	mu     sync.Mutex
+	w      *window.Window
+}
+
+func NewBudget(config *BudgetConfig) *Budget {
+	if config.Buckets <= 0 {
+		config.Buckets = DefaultBudgetConfig().Buckets
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultBudgetConfig().Window
+	}
+	return &Budget{
+		config: config,
+		w:      window.New(config.Window, config.Buckets, 2),
+	}
+}
+
+// Deposit 记录一次请求
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.w.Add(fieldRequests, 1)
+}
+
+// Withdraw 尝试消耗一次重试，预算不足时返回 false
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	requests, retries := b.w.Sum(fieldRequests), b.w.Sum(fieldRetries)
+	allowed := float64(b.config.MinPerSecond)*b.config.Window.Seconds() + b.config.Ratio*float64(requests)
+	if float64(retries) >= allowed {
+		return false
+	}
+	b.w.Add(fieldRetries, 1)
+	return true
+}
@@ -0,0 +1,141 @@
+package retry
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+type HedgingConfig struct {
+	Delay     time.Duration `mapStructure:"delay"`      // 首次请求超过该时间未返回时发出 hedge 请求，0 表示只按 p99 触发
+	UseP99    bool          `mapStructure:"use_p99"`    // 使用客户端观测到的 p99 作为触发时间，与 Delay 同时配置时取较小值
+	MaxHedges int           `mapStructure:"max_hedges"` // 每次调用最多发出的 hedge 请求数
+	Methods   []string      `mapStructure:"methods"`    // 启用 hedging 的方法，必须是幂等的读请求，支持 * 和 /pkg.Service/* 形式
+	Budget    *BudgetConfig `mapStructure:"budget"`     // 每个 target 的 hedge 预算
+}
+
+func DefaultHedgingConfig() *HedgingConfig {
+	return &HedgingConfig{
+		UseP99:    true,
+		MaxHedges: 1,
+		Budget:    DefaultBudgetConfig(),
+	}
+}
+
+// Hedger hedging 策略
+type Hedger struct {
+	config    *HedgingConfig
+	budgets   sync.Map
+	latencies sync.Map
+}
+
+func NewHedger(config *HedgingConfig) (*Hedger, error) {
+	if config == nil {
+		config = DefaultHedgingConfig()
+	}
+	if config.Budget == nil {
+		config.Budget = DefaultBudgetConfig()
+	}
+	if config.MaxHedges <= 0 {
+		config.MaxHedges = 1
+	}
+	for _, method := range config.Methods {
+		if _, err := path.Match(method, ""); err != nil {
+			return nil, fmt.Errorf("hedging method %s: %w", method, err)
+		}
+	}
+	return &Hedger{config: config}, nil
+}
+
+func (h *Hedger) MaxHedges() int {
+	return h.config.MaxHedges
+}
+
+// Enabled 判断方法是否启用 hedging
+func (h *Hedger) Enabled(method string) bool {
+	return matchMethod(h.config.Methods, method)
+}
+
+// Delay 发出 hedge 请求前的等待时间，没有可用的触发条件时返回 false
+func (h *Hedger) Delay(method string) (time.Duration, bool) {
+	delay := h.config.Delay
+	if h.config.UseP99 {
+		if p99, ok := h.latency(method).p99(); ok && (delay <= 0 || p99 < delay) {
+			delay = p99
+		}
+	}
+	return delay, delay > 0
+}
+
+// Observe 记录一次成功调用的耗时，用于计算 p99
+func (h *Hedger) Observe(method string, d time.Duration) {
+	h.latency(method).add(d)
+}
+
+// Budget 获取 target 对应的 hedge 预算
+func (h *Hedger) Budget(target string) *Budget {
+	if b, ok := h.budgets.Load(target); ok {
+		return b.(*Budget)
+	}
+	b, _ := h.budgets.LoadOrStore(target, NewBudget(h.config.Budget))
+	return b.(*Budget)
+}
+
+func (h *Hedger) latency(method string) *latency {
+	if l, ok := h.latencies.Load(method); ok {
+		return l.(*latency)
+	}
+	l, _ := h.latencies.LoadOrStore(method, &latency{samples: make([]time.Duration, latencySamples)})
+	return l.(*latency)
+}
+
+const (
+	latencySamples    = 512
+	latencyMinSamples = 100
+	latencyRefresh    = 64
+)
+
+// latency 保留最近的耗时样本，每 latencyRefresh 次重新计算 p99
+type latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	count   int
+	cached  time.Duration
+}
+
+func (l *latency) add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples[l.count%len(l.samples)] = d
+	l.count++
+	if l.count >= latencyMinSamples && (l.cached == 0 || l.count%latencyRefresh == 0) {
+		n := l.count
+		if n > len(l.samples) {
+			n = len(l.samples)
+		}
+		sorted := make([]time.Duration, n)
+		copy(sorted, l.samples[:n])
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		l.cached = sorted[(n*99-1)/100]
+	}
+}
+
+func (l *latency) p99() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cached, l.cached > 0
+}
+
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if pattern == Any || pattern == method {
+			return true
+		}
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
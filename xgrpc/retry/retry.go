@@ -0,0 +1,112 @@
+package retry
+
+import (
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"math"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+)
+
+const Any = "*"
+
+type Config struct {
+	MaxAttempts int           `mapStructure:"max_attempts"` // 包含首次调用在内的最大尝试次数
+	Codes       []string      `mapStructure:"codes"`        // 可重试的状态码，如 UNAVAILABLE, RESOURCE_EXHAUSTED
+	Methods     []string      `mapStructure:"methods"`      // 幂等方法白名单，支持 * 和 /pkg.Service/* 形式
+	BaseDelay   time.Duration `mapStructure:"base_delay"`   // 第一次重试前的等待时间
+	MaxDelay    time.Duration `mapStructure:"max_delay"`    // 等待时间上限
+	Multiplier  float64       `mapStructure:"multiplier"`   // 每次重试等待时间的倍数
+	Jitter      float64       `mapStructure:"jitter"`       // 等待时间的随机浮动比例
+	Budget      *BudgetConfig `mapStructure:"budget"`       // 每个 target 的重试预算
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		MaxAttempts: 3,
+		Codes:       []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+		Budget:      DefaultBudgetConfig(),
+	}
+}
+
+// Policy 重试策略，只有白名单中的幂等方法才会重试
+type Policy struct {
+	config  *Config
+	codes   map[codes.Code]struct{}
+	budgets sync.Map
+
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func New(config *Config) (*Policy, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.Budget == nil {
+		config.Budget = DefaultBudgetConfig()
+	}
+	p := &Policy{
+		config: config,
+		codes:  make(map[codes.Code]struct{}, len(config.Codes)),
+		r:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, name := range config.Codes {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(fmt.Sprintf("%q", name))); err != nil {
+			return nil, fmt.Errorf("retry code %s: %w", name, err)
+		}
+		p.codes[code] = struct{}{}
+	}
+	for _, method := range config.Methods {
+		if _, err := path.Match(method, ""); err != nil {
+			return nil, fmt.Errorf("retry method %s: %w", method, err)
+		}
+	}
+	return p, nil
+}
+
+func (p *Policy) MaxAttempts() int {
+	return p.config.MaxAttempts
+}
+
+// Idempotent 判断方法是否在白名单中
+func (p *Policy) Idempotent(method string) bool {
+	return matchMethod(p.config.Methods, method)
+}
+
+// Retryable 判断状态码是否可以重试
+func (p *Policy) Retryable(code codes.Code) bool {
+	_, ok := p.codes[code]
+	return ok
+}
+
+// Backoff 第 attempt 次调用失败后的等待时间，attempt 从 1 开始
+func (p *Policy) Backoff(attempt int) time.Duration {
+	delay := float64(p.config.BaseDelay) * math.Pow(p.config.Multiplier, float64(attempt-1))
+	if max := float64(p.config.MaxDelay); max > 0 && delay > max {
+		delay = max
+	}
+	p.mu.Lock()
+	delay *= 1 + p.config.Jitter*(p.r.Float64()*2-1)
+	p.mu.Unlock()
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// Budget 获取 target 对应的重试预算
+func (p *Policy) Budget(target string) *Budget {
+	if b, ok := p.budgets.Load(target); ok {
+		return b.(*Budget)
+	}
+	b, _ := p.budgets.LoadOrStore(target, NewBudget(p.config.Budget))
+	return b.(*Budget)
+}
@@ -0,0 +1,49 @@
+package retry
+
+import (
+	"google.golang.org/grpc/codes"
+	"testing"
+	"time"
+)
+
+func TestPolicy(t *testing.T) {
+	p, err := New(&Config{
+		MaxAttempts: 3,
+		Codes:       []string{"UNAVAILABLE"},
+		Methods:     []string{"/pkg.User/*", "/pkg.Order/Get"},
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    300 * time.Millisecond,
+		Multiplier:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Idempotent("/pkg.User/Get") || !p.Idempotent("/pkg.Order/Get") || p.Idempotent("/pkg.Order/Create") {
+		t.Fatal("unexpected idempotent match")
+	}
+	if !p.Retryable(codes.Unavailable) || p.Retryable(codes.Internal) {
+		t.Fatal("unexpected retryable code")
+	}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond} {
+		if got := p.Backoff(attempt + 1); got != want {
+			t.Fatalf("backoff(%d) = %s, want %s", attempt+1, got, want)
+		}
+	}
+
+	if _, err := New(&Config{Codes: []string{"NOT_A_CODE"}}); err == nil {
+		t.Fatal("want error for unknown code")
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(&BudgetConfig{Ratio: 0.5, MinPerSecond: 0, Window: time.Second, Buckets: 10})
+	for i := 0; i < 4; i++ {
+		b.Deposit()
+	}
+	if !b.Withdraw() || !b.Withdraw() {
+		t.Fatal("budget should allow 2 retries")
+	}
+	if b.Withdraw() {
+		t.Fatal("budget should be exhausted")
+	}
+}
@@ -0,0 +1,55 @@
+package serverinterceptors
+
+import (
+	"context"
+	"errors"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrUnauthenticated 认证失败
+var ErrUnauthenticated = xcode.SystemCodeAdd(uint32(codes.Unauthenticated), "unauthenticated")
+
+func authenticate(ctx context.Context, config *auth.Config, verifier auth.Verifier, method string) (context.Context, error) {
+	if config.Skip(method) {
+		return ctx, nil
+	}
+	principal, err := verifier.Verify(ctx)
+	if err != nil {
+		if !errors.Is(err, auth.ErrNoCredentials) {
+			xlog.Warn("GRPC Authentication Failed",
+				xlog.FieldComponentName("GRPC"),
+				xlog.FieldMethod(method),
+				xlog.FieldType("server"),
+				xlog.FieldAddr(CallerFromContext(ctx).PeerAddr),
+				xlog.FieldErr(err),
+			)
+		}
+		return ctx, ErrUnauthenticated
+	}
+	return auth.NewContext(ctx, principal), nil
+}
+
+// AuthUnaryServerInterceptor 校验调用方身份，通过后可以用 auth.FromContext 获取 Principal
+func AuthUnaryServerInterceptor(config *auth.Config, verifier auth.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, config, verifier, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthStreamServerInterceptor(config *auth.Config, verifier auth.Verifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), config, verifier, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, contextedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,65 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/authz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestAuthUnaryServerInterceptor(t *testing.T) {
+	config := auth.DefaultConfig()
+	config.APIKey.Keys = []auth.APIKey{{Key: "k1", Name: "order"}}
+	interceptor := AuthUnaryServerInterceptor(config, auth.NewAPIKeyVerifier(config.APIKey))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p, _ := auth.FromContext(ctx)
+		return p, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k1"))
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}, handler)
+	if err != nil || resp.(*auth.Principal).Name != "order" {
+		t.Fatalf("resp %+v err %v", resp, err)
+	}
+
+	if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}, handler); err != ErrUnauthenticated {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 健康检查不需要认证
+	if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestAuthzUnaryServerInterceptor(t *testing.T) {
+	interceptor := AuthzUnaryServerInterceptor(authz.NewWithConfig("server-test", &authz.Config{
+		Default: authz.Deny,
+		Rules: []authz.Rule{
+			{Effect: authz.Allow, Callers: []string{"order"}, Methods: []string{"/user.User/*"}},
+			{Effect: authz.Allow, Callers: []string{"*"}, Methods: []string{"/user.User/List"}},
+			{Effect: authz.Allow, Methods: []string{"/grpc.health.v1.Health/*"}},
+		},
+	}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+
+	// 认证得到的身份优先于 metadata 中的 app_name
+	ctx := auth.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs("app_name", "gateway")), &auth.Principal{Name: "order"})
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	// 没有认证身份时不信任 app_name，也不匹配限制了调用方的规则
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("app_name", "order"))
+	if _, err := interceptor(ctx, nil, info, handler); err != ErrPermissionDenied {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.User/List"}, handler); err != ErrPermissionDenied {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
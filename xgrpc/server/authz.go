@@ -0,0 +1,67 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/authz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrPermissionDenied 调用方没有权限调用该方法
+var ErrPermissionDenied = xcode.SystemCodeAdd(uint32(codes.PermissionDenied), "permission denied")
+
+// authzRequest 只使用认证拦截器得到的调用方名称，metadata 中的 app_name 由客户端填写，不能用于授权
+// 没有认证身份时 Caller 为空，只能匹配不限制调用方的规则
+func authzRequest(ctx context.Context, method string) authz.Request {
+	req := authz.Request{Method: method, Metadata: make(map[string]string)}
+	if p, ok := auth.FromContext(ctx); ok {
+		req.Caller = p.Name
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, v := range md {
+			if len(v) > 0 {
+				req.Metadata[k] = v[0]
+			}
+		}
+	}
+	return req
+}
+
+func authorize(ctx context.Context, e *authz.Engine, method string) error {
+	req := authzRequest(ctx, method)
+	decision := e.Evaluate(req)
+	if decision.Allowed {
+		return nil
+	}
+	xlog.Warn("GRPC Permission Denied",
+		xlog.FieldComponentName("GRPC"),
+		xlog.FieldMethod(method),
+		xlog.FieldType("server"),
+		xlog.FieldName(req.Caller),
+		xlog.FieldDescription(decision.Reason),
+	)
+	return ErrPermissionDenied
+}
+
+// AuthzUnaryServerInterceptor 按授权规则判断调用方是否可以调用该方法，应放在 auth 拦截器之后
+func AuthzUnaryServerInterceptor(e *authz.Engine) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, e, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthzStreamServerInterceptor(e *authz.Engine) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), e, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
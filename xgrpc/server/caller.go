@@ -0,0 +1,53 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-saber/xjson"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Caller 调用方身份，来自客户端 XAid 拦截器写入的 metadata
+type Caller struct {
+	AppName  string `json:"app_name"`
+	HostName string `json:"host_name"`
+	AppId    string `json:"app_id"`
+	HostIP   string `json:"host_ip"`
+	PeerAddr string `json:"-"`
+}
+
+// CallerFromContext 优先解析 info，缺失的字段再从 app_name/ip/app_id/host_name 中读取
+func CallerFromContext(ctx context.Context) Caller {
+	var caller Caller
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if info := md.Get("info"); len(info) > 0 {
+			_ = xjson.Unmarshal([]byte(info[0]), &caller)
+		}
+		if caller.AppName == "" {
+			caller.AppName = first(md, "app_name")
+		}
+		if caller.HostIP == "" {
+			caller.HostIP = first(md, "ip")
+		}
+		if caller.AppId == "" {
+			caller.AppId = first(md, "app_id")
+		}
+		if caller.HostName == "" {
+			caller.HostName = first(md, "host_name")
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		caller.PeerAddr = p.Addr.String()
+	}
+	if caller.AppName == "" {
+		caller.AppName = "unknown"
+	}
+	return caller
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"time"
+)
+
+// ErrDeadlineExceeded 请求到达时超时预算已经耗尽
+var ErrDeadlineExceeded = xcode.SystemCodeAdd(uint32(codes.DeadlineExceeded), "deadline budget exhausted")
+
+// XDeadlineUnaryServerInterceptor 按上游传递的超时预算设置 deadline，上游没有传递时使用 timeout
+// 预算扣除 reserve(留给响应回传的网络耗时)后已经耗尽的请求直接拒绝
+func XDeadlineUnaryServerInterceptor(timeout, reserve time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		budget, ok := deadline.Budget(ctx, timeout, reserve)
+		if !ok {
+			return nil, ErrDeadlineExceeded
+		}
+		if budget <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, budget)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// XDeadlineStreamServerInterceptor 流通常是长连接，只按上游预算设置 deadline，不设置默认超时
+func XDeadlineStreamServerInterceptor(reserve time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		budget, ok := deadline.Budget(ss.Context(), 0, reserve)
+		if !ok {
+			return ErrDeadlineExceeded
+		}
+		if budget <= 0 {
+			return handler(srv, ss)
+		}
+		ctx, cancel := context.WithTimeout(ss.Context(), budget)
+		defer cancel()
+		return handler(srv, contextedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
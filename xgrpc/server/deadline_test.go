@@ -0,0 +1,66 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"testing"
+	"time"
+)
+
+func TestXTimeoutUnaryServerInterceptor(t *testing.T) {
+	interceptor := XTimeoutUnaryServerInterceptor(time.Second)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+	left := func(ctx context.Context, req interface{}) (interface{}, error) {
+		dl, _ := ctx.Deadline()
+		return time.Until(dl), nil
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _ = interceptor(short, nil, info, left)
+
+	// 短 deadline 的请求不能影响之后的请求
+	resp, _ := interceptor(context.Background(), nil, info, left)
+	if d := resp.(time.Duration); d < 900*time.Millisecond {
+		t.Fatalf("timeout shrunk to %s", d)
+	}
+}
+
+func TestXDeadlineUnaryServerInterceptor(t *testing.T) {
+	interceptor := XDeadlineUnaryServerInterceptor(time.Second, 10*time.Millisecond)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		dl, _ := ctx.Deadline()
+		return time.Until(dl), nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(deadline.BudgetKey, "5"))
+	if _, err := interceptor(ctx, nil, info, handler); err != ErrDeadlineExceeded || called {
+		t.Fatalf("err = %v, called = %v", err, called)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(deadline.BudgetKey, "200"))
+	resp, err := interceptor(ctx, nil, info, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := resp.(time.Duration); d > 190*time.Millisecond || d < 150*time.Millisecond {
+		t.Fatalf("budget = %s", d)
+	}
+
+	// 上游预算大于本地 timeout 时不被截断
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(deadline.BudgetKey, "3000"))
+	resp, _ = interceptor(ctx, nil, info, handler)
+	if d := resp.(time.Duration); d < 2900*time.Millisecond {
+		t.Fatalf("budget = %s", d)
+	}
+
+	resp, _ = interceptor(context.Background(), nil, info, handler)
+	if d := resp.(time.Duration); d > time.Second || d < 900*time.Millisecond {
+		t.Fatalf("timeout = %s", d)
+	}
+}
@@ -0,0 +1,46 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xcode/i18n"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+)
+
+// localize 为错误附加 LocalizedMessage 详情，没有对应翻译或已经附加过时原样返回
+func localize(ctx context.Context, resolver i18n.Resolver, err error) error {
+	if err == nil {
+		return nil
+	}
+	st := xcode.ExtractCodes(err)
+	if st.IsOk() {
+		return err
+	}
+	if _, ok := st.LocalizedMessage(""); ok {
+		return err
+	}
+	locale, message, ok := st.Localize(resolver(ctx)...)
+	if !ok {
+		return err
+	}
+	localized, e := st.WithDetails(&errdetails.LocalizedMessage{Locale: locale, Message: message})
+	if e != nil {
+		return err
+	}
+	return localized
+}
+
+// I18nUnaryServerInterceptor 按调用方的 accept-language 为错误附加 LocalizedMessage 详情
+func I18nUnaryServerInterceptor(resolver i18n.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, localize(ctx, resolver, err)
+	}
+}
+
+func I18nStreamServerInterceptor(resolver i18n.Resolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return localize(ss.Context(), resolver, handler(srv, ss))
+	}
+}
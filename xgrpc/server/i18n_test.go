@@ -0,0 +1,35 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xcode/i18n"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestI18nUnaryServerInterceptor(t *testing.T) {
+	errNotFound := xcode.BusinessCodeAdd(50001, "not found", xcode.Translation{Locale: "zh-CN", Message: "未找到"})
+	interceptor := I18nUnaryServerInterceptor((&i18n.Config{DefaultLocale: "en"}).Resolver())
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errNotFound
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(i18n.AcceptLanguageKey, "zh-CN,zh;q=0.9"))
+	_, err := interceptor(ctx, nil, info, handler)
+	msg, ok := xcode.ExtractCodes(err).LocalizedMessage("")
+	if !ok || msg.Locale != "zh-CN" || msg.Message != "未找到" {
+		t.Fatalf("unexpected localized message: %v", msg)
+	}
+	if len(errNotFound.Details) != 0 {
+		t.Fatal("registered status must not be modified")
+	}
+
+	// 没有对应翻译时不附加详情
+	_, err = interceptor(context.Background(), nil, info, handler)
+	if _, ok := xcode.ExtractCodes(err).LocalizedMessage(""); ok {
+		t.Fatal("unexpected localized message")
+	}
+}
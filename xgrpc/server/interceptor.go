@@ -30,7 +30,7 @@ func toPanicError(r interface{}) error {
 	stack := debug.Stack()
 	buf.Write(stack)
 	xlog.Error(fmt.Sprintf("%+v", r), xlog.FieldValue(buf.String()))
-	return xcode.SystemCodeAdd(xcast.ToUint32(codes.Internal), "server internal error")
+	return xcode.SystemCodeAdd(uint32(codes.Internal), "server internal error")
 }
 
 func CrashUnaryServerInterceptor() grpc.UnaryServerInterceptor {
@@ -132,13 +132,14 @@ func TraceStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *g
 func XTimeoutUnaryServerInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		// 不能修改闭包捕获的 timeout，否则一次短 deadline 的请求会影响之后所有请求
+		left := timeout
 		if deadline, ok := ctx.Deadline(); ok {
-			leftTime := time.Until(deadline)
-			if leftTime < timeout {
-				timeout = leftTime
+			if d := time.Until(deadline); d < left {
+				left = d
 			}
 		}
-		ctx, cancel := context.WithDeadline(ctx, time.Now().Add(timeout))
+		ctx, cancel := context.WithTimeout(ctx, left)
 		defer cancel()
 		return handler(ctx, req)
 	}
@@ -0,0 +1,94 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-saber/xcast"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xlogger"
+	"google.golang.org/grpc"
+	"math/rand"
+	"time"
+)
+
+type LoggerConfig struct {
+	SlowThreshold     time.Duration      `mapStructure:"slow_threshold"`      // 超过该耗时的请求记录为慢请求
+	EnablePayload     bool               `mapStructure:"enable_payload"`      // 是否记录请求和响应内容，可通过 xgovern /debug/log/payload 临时调整
+	SampleRate        float64            `mapStructure:"sample_rate"`         // 正常请求的采样率 0~1，错误和慢请求总是记录
+	MethodSampleRates map[string]float64 `mapStructure:"method_sample_rates"` // 按 FullMethod 覆盖采样率
+}
+
+func DefaultLoggerConfig() *LoggerConfig {
+	return &LoggerConfig{
+		SlowThreshold: time.Second,
+		EnablePayload: false,
+		SampleRate:    1,
+	}
+}
+
+func (config *LoggerConfig) sampled(method string) bool {
+	rate := config.SampleRate
+	if r, ok := config.MethodSampleRates[method]; ok {
+		rate = r
+	}
+	if rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+func (config *LoggerConfig) log(ctx context.Context, typ, method string, cost time.Duration, err error, req, resp interface{}) {
+	spbStatus := xcode.ExtractCodes(err)
+	slow := config.SlowThreshold > time.Duration(0) && cost > config.SlowThreshold
+	if err == nil && !slow && !config.sampled(method) {
+		return
+	}
+
+	caller := CallerFromContext(ctx)
+	fields := []xlog.Field{
+		xlog.FieldType("server"),
+		xlog.FieldType(typ),
+		xlog.FieldMethod(method),
+		xlog.FieldCode(spbStatus.Code),
+		xlog.FieldCost(cost),
+		xlog.FieldPeerName(caller.AppName),
+		xlog.FieldPeerIP(caller.HostIP),
+		xlog.FieldAddr(caller.PeerAddr),
+	}
+	payload := config.EnablePayload
+	if enabled, ok := xlogger.Payload(); ok {
+		payload = enabled
+	}
+	if payload {
+		fields = append(fields, xlog.Any("req", req), xlog.Any("resp", resp))
+	}
+
+	switch {
+	case err != nil && spbStatus.Code < xcast.ToInt32(xcode.CodeBreakUp):
+		xlog.Error("GRPC Server Internal Error", append(fields, xlog.FieldErrKind(spbStatus.Message))...)
+	case err != nil:
+		xlog.Warn("GRPC Business Error", append(fields, xlog.FieldErrKind(spbStatus.Message))...)
+	case slow:
+		xlog.Warn("GRPC Slow Request", fields...)
+	default:
+		xlog.Info("GRPC Access", fields...)
+	}
+}
+
+func XLoggerUnaryServerInterceptor(config *LoggerConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		beg := time.Now()
+		resp, err := handler(ctx, req)
+		config.log(ctx, "unary", info.FullMethod, time.Since(beg), err, req, resp)
+		return resp, err
+	}
+}
+
+func XLoggerStreamServerInterceptor(config *LoggerConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		beg := time.Now()
+		err := handler(srv, ss)
+		config.log(ss.Context(), "stream", info.FullMethod, time.Since(beg), err, nil, nil)
+		return err
+	}
+}
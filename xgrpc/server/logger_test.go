@@ -0,0 +1,41 @@
+package serverinterceptors
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestCallerFromContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"info", `{"app_name":"order","host_name":"host-1","app_id":"1","host_ip":"10.0.0.1"}`,
+		"ip", "10.0.0.2",
+	))
+	caller := CallerFromContext(ctx)
+	if caller.AppName != "order" || caller.HostIP != "10.0.0.1" || caller.HostName != "host-1" {
+		t.Fatalf("unexpected caller %+v", caller)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("app_name", "user", "ip", "10.0.0.3"))
+	caller = CallerFromContext(ctx)
+	if caller.AppName != "user" || caller.HostIP != "10.0.0.3" {
+		t.Fatalf("unexpected caller %+v", caller)
+	}
+
+	if caller = CallerFromContext(context.Background()); caller.AppName != "unknown" {
+		t.Fatalf("unexpected caller %+v", caller)
+	}
+}
+
+func TestLoggerSampled(t *testing.T) {
+	config := &LoggerConfig{
+		SampleRate:        1,
+		MethodSampleRates: map[string]float64{"/health/Check": 0},
+	}
+	if !config.sampled("/user/Get") {
+		t.Fatal("expect sampled")
+	}
+	if config.sampled("/health/Check") {
+		t.Fatal("expect not sampled")
+	}
+}
@@ -0,0 +1,48 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/ratelimit"
+	"github.com/coder2z/g-server/xmonitor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrResourceExhausted 触发限流或降载时返回的系统错误码
+var ErrResourceExhausted = xcode.SystemCodeAdd(uint32(codes.ResourceExhausted), "resource exhausted")
+
+// rateLimitCaller 限流使用的调用方名称，优先使用认证得到的身份，没有启用认证时使用 metadata 中的 app_name；
+// app_name 由客户端填写，可以伪造，按调用方分配配额时应在 auth 之后启用 ratelimit
+func rateLimitCaller(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.Name
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		return first(md, "app_name")
+	}
+	return ""
+}
+
+func RateLimitUnaryServerInterceptor(m *ratelimit.Manager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !m.Allow(info.FullMethod, rateLimitCaller(ctx)) {
+			xmonitor.ServerRateLimitCounter.WithLabelValues(xmonitor.TypeGRPCUnary, xapp.Name(), info.FullMethod).Inc()
+			return nil, ErrResourceExhausted
+		}
+		return handler(ctx, req)
+	}
+}
+
+func RateLimitStreamServerInterceptor(m *ratelimit.Manager) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !m.Allow(info.FullMethod, rateLimitCaller(ss.Context())) {
+			xmonitor.ServerRateLimitCounter.WithLabelValues(xmonitor.TypeGRPCStream, xapp.Name(), info.FullMethod).Inc()
+			return ErrResourceExhausted
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,32 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestRateLimitCaller(t *testing.T) {
+	interceptor := RateLimitUnaryServerInterceptor(ratelimit.NewWithConfig(&ratelimit.Config{Rules: []ratelimit.Rule{
+		{Caller: "order", Rate: 0.001, Burst: 1},
+	}}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Get"}
+
+	// 没有认证身份时使用 app_name
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("app_name", "order"))
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := interceptor(ctx, nil, info, handler); err != ErrResourceExhausted {
+		t.Fatalf("err = %v", err)
+	}
+	// 认证身份优先于 app_name
+	ctx = auth.NewContext(ctx, &auth.Principal{Name: "pay"})
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
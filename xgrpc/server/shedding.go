@@ -0,0 +1,29 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/shedding"
+	"google.golang.org/grpc"
+)
+
+// SheddingUnaryServerInterceptor 自适应降载，被拒绝的请求统计在 xmonitor.SheddingGauge 中
+func SheddingUnaryServerInterceptor(s *shedding.Shedder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done, err := s.Allow()
+		if err != nil {
+			return nil, ErrResourceExhausted
+		}
+		defer done()
+		return handler(ctx, req)
+	}
+}
+
+// SheddingStreamServerInterceptor 流的生命周期不代表处理耗时，只在建立时做准入判断
+func SheddingStreamServerInterceptor(s *shedding.Shedder) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := s.Admit(); err != nil {
+			return ErrResourceExhausted
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,66 @@
+package serverinterceptors
+
+import (
+	"context"
+	"errors"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/validate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrInvalidArgument 请求参数校验不通过，details 中携带 errdetails.BadRequest
+var ErrInvalidArgument = xcode.SystemCodeAdd(uint32(codes.InvalidArgument), "invalid argument")
+
+// invalidArgument 将校验错误转换为带 BadRequest 详情的状态
+func invalidArgument(err error) error {
+	var violations validate.Violations
+	if !errors.As(err, &violations) {
+		violations = validate.Violations{{Description: err.Error()}}
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	st, e := ErrInvalidArgument.WithDetails(badRequest)
+	if e != nil {
+		return ErrInvalidArgument
+	}
+	// WithDetails 返回的是副本，修改消息不会影响注册的错误码
+	return st.SetMsg(violations.Error())
+}
+
+// ValidateUnaryServerInterceptor 调用 handler 前执行请求的 Validate 方法和 validate.Register 注册的规则
+func ValidateUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validate.Validate(req); err != nil {
+			return nil, invalidArgument(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidateStreamServerInterceptor 校验流中收到的每一条消息
+func ValidateStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validateServerStream{ServerStream: ss})
+	}
+}
+
+type validateServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validateServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := validate.Validate(m); err != nil {
+		return invalidArgument(err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package serverinterceptors
+
+import (
+	"context"
+	"github.com/coder2z/g-server/xgrpc/validate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+type validateRequest struct {
+	Name string `json:"name"`
+}
+
+func TestValidateUnaryServerInterceptor(t *testing.T) {
+	validate.Register((*validateRequest)(nil), validate.Field("name", validate.Required()))
+	interceptor := ValidateUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.User/Create"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	if _, err := interceptor(context.Background(), &validateRequest{Name: "tom"}, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := interceptor(context.Background(), &validateRequest{}, info, handler)
+	st := status.Convert(err)
+	if uint32(st.Code()) != ErrInvalidArgument.GetCodeAsUint32() {
+		t.Fatalf("want %d, got %v", ErrInvalidArgument.GetCodeAsUint32(), st.Code())
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("want 1 detail, got %v", st.Details())
+	}
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != "name" {
+		t.Fatalf("unexpected detail: %v", st.Details()[0])
+	}
+	if ErrInvalidArgument.Message != "invalid argument" {
+		t.Fatalf("registered message mutated: %s", ErrInvalidArgument.Message)
+	}
+}
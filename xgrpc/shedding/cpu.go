@@ -0,0 +1,172 @@
+package shedding
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cpuInterval = 250 * time.Millisecond
+	cpuDecay    = 0.95
+	// /proc/self/stat 中 utime/stime 的单位，绝大多数 Linux 为 100Hz
+	clockTicks = 100
+	// cgroupRoot cgroup 文件系统的挂载点
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+var (
+	cpuUsage int64 // 进程 CPU 使用率，千分比
+	cpuOnce  sync.Once
+)
+
+// CPU 返回平滑后的进程 CPU 使用率(0~1000)，非 Linux 平台始终为0；
+// 容器通过 cgroup 限制了 CPU quota 时相对于 quota 计算，否则相对于 GOMAXPROCS
+func CPU() int64 {
+	return atomic.LoadInt64(&cpuUsage)
+}
+
+func startCPUSampler() {
+	cpuOnce.Do(func() {
+		go func() {
+			lastCPU, ok := processCPUTime()
+			if !ok {
+				return
+			}
+			lastTime := time.Now()
+			limit := cpuLimit()
+			ticker := time.NewTicker(cpuInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				cur, ok := processCPUTime()
+				if !ok {
+					continue
+				}
+				now := time.Now()
+				wall := now.Sub(lastTime).Seconds() * limit
+				usage := float64(0)
+				if wall > 0 {
+					usage = (cur - lastCPU) / wall * 1000
+				}
+				if usage > 1000 {
+					usage = 1000
+				}
+				prev := atomic.LoadInt64(&cpuUsage)
+				atomic.StoreInt64(&cpuUsage, int64(float64(prev)*cpuDecay+usage*(1-cpuDecay)))
+				lastCPU, lastTime = cur, now
+			}
+		}()
+	})
+}
+
+// processCPUTime 读取进程累计使用的 CPU 秒数
+func processCPUTime() (float64, bool) {
+	b, err := ioutil.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, false
+	}
+	// 第二个字段为进程名，可能包含空格，从右括号之后开始解析
+	stat := string(b)
+	if i := strings.LastIndexByte(stat, ')'); i >= 0 {
+		stat = stat[i+1:]
+	}
+	fields := strings.Fields(stat)
+	// 去掉前两个字段后 utime/stime 位于第 12、13 个
+	if len(fields) < 13 {
+		return 0, false
+	}
+	utime, err := strconv.ParseFloat(fields[11], 64)
+	if err != nil {
+		return 0, false
+	}
+	stime, err := strconv.ParseFloat(fields[12], 64)
+	if err != nil {
+		return 0, false
+	}
+	return (utime + stime) / clockTicks, true
+}
+
+// cpuLimit 进程可用的 CPU 核数，取 cgroup CPU quota 和 GOMAXPROCS 中较小的一个
+func cpuLimit() float64 {
+	limit := float64(runtime.GOMAXPROCS(0))
+	if quota, ok := cgroupCPUQuota(); ok && quota < limit {
+		return quota
+	}
+	return limit
+}
+
+// cgroupCPUQuota 读取 cgroup v2 的 cpu.max 或 v1 的 cpu.cfs_quota_us，未限制时返回 false；
+// 容器内通常挂载的就是自身的 cgroup，按 /proc/self/cgroup 中的路径找不到时使用挂载点根目录
+func cgroupCPUQuota() (float64, bool) {
+	v2, v1 := cgroupPaths()
+	for _, dir := range []string{filepath.Join(cgroupRoot, v2), cgroupRoot} {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, "cpu.max")); err == nil {
+			return parseCPUMax(string(b))
+		}
+	}
+	for _, base := range []string{filepath.Join(cgroupRoot, "cpu"), filepath.Join(cgroupRoot, "cpu,cpuacct")} {
+		for _, dir := range []string{filepath.Join(base, v1), base} {
+			quota, err := ioutil.ReadFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+			if err != nil {
+				continue
+			}
+			period, err := ioutil.ReadFile(filepath.Join(dir, "cpu.cfs_period_us"))
+			if err != nil {
+				continue
+			}
+			return parseCFSQuota(string(quota), string(period))
+		}
+	}
+	return 0, false
+}
+
+// cgroupPaths /proc/self/cgroup 中 v2 和 v1 cpu 控制器的路径
+func cgroupPaths() (v2, v1 string) {
+	b, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", ""
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			v2 = parts[2]
+			continue
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			if c == "cpu" {
+				v1 = parts[2]
+			}
+		}
+	}
+	return v2, v1
+}
+
+// parseCPUMax cpu.max 格式为 "$MAX $PERIOD"，$MAX 为 max 表示不限制
+func parseCPUMax(s string) (float64, bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+	return parseCFSQuota(fields[0], fields[1])
+}
+
+// parseCFSQuota quota 为 -1 表示不限制
+func parseCFSQuota(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(strings.TrimSpace(quota), 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(strings.TrimSpace(period), 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
@@ -0,0 +1,56 @@
+package shedding
+
+import (
+	"github.com/coder2z/g-saber/xjson"
+	"github.com/coder2z/g-server/xgovern"
+	"github.com/coder2z/g-server/xmonitor"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	shedders    sync.Map
+	monitorOnce sync.Once
+)
+
+func init() {
+	xgovern.HandleFunc("/debug/shedding", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_ = xjson.NewEncoder(w).Encode(Stats())
+	})
+}
+
+// Stats 所有降载器的当前状态
+func Stats() map[string]Stat {
+	stats := make(map[string]Stat)
+	shedders.Range(func(key, value interface{}) bool {
+		stats[key.(string)] = value.(*Shedder).Stat()
+		return true
+	})
+	return stats
+}
+
+func register(s *Shedder) {
+	shedders.Store(s.name, s)
+	monitorOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				for name, stat := range Stats() {
+					dropping := float64(0)
+					if stat.Dropping {
+						dropping = 1
+					}
+					xmonitor.SheddingGauge.WithLabelValues(name, "cpu").Set(float64(stat.CPU))
+					xmonitor.SheddingGauge.WithLabelValues(name, "in_flight").Set(float64(stat.InFlight))
+					xmonitor.SheddingGauge.WithLabelValues(name, "max_in_flight").Set(float64(stat.MaxInFlight))
+					xmonitor.SheddingGauge.WithLabelValues(name, "min_rt").Set(float64(stat.MinRT))
+					xmonitor.SheddingGauge.WithLabelValues(name, "dropping").Set(dropping)
+					xmonitor.SheddingGauge.WithLabelValues(name, "dropped").Set(float64(stat.Dropped))
+				}
+			}
+		}()
+	})
+}
@@ -0,0 +1,183 @@
+package shedding
+
+import (
+	"errors"
+	"github.com/coder2z/g-server/xgrpc/internal/window"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrServiceOverloaded 请求被降载
+var ErrServiceOverloaded = errors.New("service overloaded")
+
+type Config struct {
+	Window       time.Duration `mapStructure:"window"`        // 统计窗口
+	Buckets      int           `mapStructure:"buckets"`       // 窗口切分的桶数
+	CPUThreshold int64         `mapStructure:"cpu_threshold"` // CPU 千分比超过该值开始降载
+	CoolDown     time.Duration `mapStructure:"cool_down"`     // 降载后的冷却时间，期间继续按并发判断
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Window:       5 * time.Second,
+		Buckets:      50,
+		CPUThreshold: 800,
+		CoolDown:     time.Second,
+	}
+}
+
+// Stat 降载状态
+type Stat struct {
+	CPU         int64 `json:"cpu"`
+	InFlight    int64 `json:"in_flight"`
+	MaxInFlight int64 `json:"max_in_flight"`
+	MaxPass     int64 `json:"max_pass"`
+	MinRT       int64 `json:"min_rt"`
+	Dropping    bool  `json:"dropping"`
+	Dropped     int64 `json:"dropped"`
+}
+
+// Shedder BBR 风格的自适应降载：
+// CPU 超过阈值(或处于冷却期)且并发超过 maxPass * minRT 估算的容量时拒绝请求
+type Shedder struct {
+	name            string
+	config          *Config
+	stat            *stat
+	bucketPerSecond float64
+	inFlight        int64
+	dropped         int64
+	prevDrop        atomic.Value
+}
+
+// New 创建降载器，同名的降载器会在 /debug/shedding 和监控中导出
+func New(name string, config *Config) *Shedder {
+	if config.Buckets <= 0 {
+		config.Buckets = DefaultConfig().Buckets
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultConfig().Window
+	}
+	w := newStat(config.Window, config.Buckets)
+	s := &Shedder{
+		name:            name,
+		config:          config,
+		stat:            w,
+		bucketPerSecond: float64(time.Second) / float64(w.w.Size()),
+	}
+	s.prevDrop.Store(time.Time{})
+	startCPUSampler()
+	register(s)
+	return s
+}
+
+// Allow 判断是否放行，放行时返回的 done 必须在请求结束时调用
+func (s *Shedder) Allow() (func(), error) {
+	if err := s.Admit(); err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&s.inFlight, 1)
+	start := time.Now()
+	return func() {
+		rt := int64(math.Ceil(float64(time.Since(start)) / float64(time.Millisecond)))
+		s.stat.add(rt)
+		atomic.AddInt64(&s.inFlight, -1)
+	}, nil
+}
+
+// Admit 只做准入判断，不占用并发也不记录耗时，用于生命周期不代表处理耗时的流式请求
+func (s *Shedder) Admit() error {
+	if s.shouldDrop() {
+		s.prevDrop.Store(time.Now())
+		atomic.AddInt64(&s.dropped, 1)
+		return ErrServiceOverloaded
+	}
+	return nil
+}
+
+func (s *Shedder) Name() string {
+	return s.name
+}
+
+func (s *Shedder) Stat() Stat {
+	maxPass, minRT := s.stat.maxPass(), s.stat.minRT()
+	return Stat{
+		CPU:         CPU(),
+		InFlight:    atomic.LoadInt64(&s.inFlight),
+		MaxInFlight: s.maxInFlight(maxPass, minRT),
+		MaxPass:     maxPass,
+		MinRT:       minRT,
+		Dropping:    s.coolingDown(),
+		Dropped:     atomic.LoadInt64(&s.dropped),
+	}
+}
+
+func (s *Shedder) maxInFlight(maxPass, minRT int64) int64 {
+	return int64(math.Floor(float64(maxPass*minRT)*s.bucketPerSecond/1000.0 + 0.5))
+}
+
+func (s *Shedder) coolingDown() bool {
+	prev := s.prevDrop.Load().(time.Time)
+	return !prev.IsZero() && time.Since(prev) <= s.config.CoolDown
+}
+
+func (s *Shedder) shouldDrop() bool {
+	if CPU() < s.config.CPUThreshold && !s.coolingDown() {
+		return false
+	}
+	inFlight := atomic.LoadInt64(&s.inFlight)
+	return inFlight > 1 && inFlight > s.maxInFlight(s.stat.maxPass(), s.stat.minRT())
+}
+
+const (
+	fieldPass = iota
+	fieldRT
+)
+
+// stat 记录窗口内每个桶的通过数和耗时
+type stat struct {
+	mu sync.Mutex
+	w  *window.Window
+}
+
+func newStat(length time.Duration, n int) *stat {
+	return &stat{w: window.New(length, n, 2)}
+}
+
+func (s *stat) add(rt int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.w.Current()
+	b[fieldPass]++
+	b[fieldRT] += rt
+}
+
+// maxPass 已完成的桶中最大的通过数，没有数据时为1
+func (s *stat) maxPass() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := int64(1)
+	s.w.Completed(func(b []int64) {
+		if b[fieldPass] > result {
+			result = b[fieldPass]
+		}
+	})
+	return result
+}
+
+// minRT 已完成的桶中最小的平均耗时(毫秒)，没有数据时不限制
+func (s *stat) minRT() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := int64(math.MaxInt32)
+	s.w.Completed(func(b []int64) {
+		if b[fieldPass] == 0 {
+			return
+		}
+		if avg := int64(math.Ceil(float64(b[fieldRT]) / float64(b[fieldPass]))); avg < result {
+			result = avg
+		}
+	})
+	return result
+}
@@ -0,0 +1,90 @@
+package shedding
+
+import (
+	"math"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestShedder(t *testing.T) {
+	s := New("test", &Config{
+		Window:       time.Second,
+		Buckets:      10,
+		CPUThreshold: 0, // 始终认为 CPU 超过阈值
+		CoolDown:     time.Second,
+	})
+
+	for i := 0; i < 5; i++ {
+		done, err := s.Allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		done()
+	}
+	// 等待当前桶结束后统计才生效
+	time.Sleep(150 * time.Millisecond)
+
+	if stat := s.Stat(); stat.MaxPass != 5 || stat.MinRT != 1 {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+
+	done1, err := s.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done1()
+	done2, err := s.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done2()
+	if _, err := s.Allow(); err != ErrServiceOverloaded {
+		t.Fatalf("err = %v, want overloaded", err)
+	}
+	if err := s.Admit(); err != ErrServiceOverloaded {
+		t.Fatalf("admit err = %v, want overloaded", err)
+	}
+	if stat := Stats()["test"]; !stat.Dropping || stat.Dropped != 2 {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+}
+
+func TestShedderAdmit(t *testing.T) {
+	s := New("admit", &Config{Window: time.Second, Buckets: 10, CPUThreshold: 1000})
+	for i := 0; i < 3; i++ {
+		if err := s.Admit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(150 * time.Millisecond)
+	// 准入判断不占用并发也不记录耗时
+	if stat := s.Stat(); stat.InFlight != 0 || stat.MaxPass != 1 || stat.MinRT != math.MaxInt32 {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+}
+
+func TestShedderLongBucket(t *testing.T) {
+	// 桶长度 2s 时每秒 0.5 个桶，容量不能被截断为 0
+	s := New("long_bucket", &Config{Window: 20 * time.Second, Buckets: 10, CPUThreshold: 1000})
+	if n := s.maxInFlight(1000, 10); n != 5 {
+		t.Fatalf("max in flight = %d, want 5", n)
+	}
+}
+
+func TestCgroupQuota(t *testing.T) {
+	for s, want := range map[string]float64{"200000 100000\n": 2, "50000 100000": 0.5, "max 100000\n": 0, "": 0} {
+		if got, ok := parseCPUMax(s); got != want || ok != (want > 0) {
+			t.Fatalf("cpu.max %q = %v, %v", s, got, ok)
+		}
+	}
+	if got, ok := parseCFSQuota("150000\n", "100000\n"); !ok || got != 1.5 {
+		t.Fatalf("cfs quota = %v, %v", got, ok)
+	}
+	if _, ok := parseCFSQuota("-1\n", "100000\n"); ok {
+		t.Fatal("-1 means no quota")
+	}
+	if limit := cpuLimit(); limit <= 0 || limit > float64(runtime.GOMAXPROCS(0)) {
+		t.Fatalf("cpu limit = %v", limit)
+	}
+}
@@ -0,0 +1,110 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Check 校验字段的值，不通过时返回错误描述，通过时返回空字符串
+// 值为无效的 reflect.Value 表示路径上有 nil 指针
+type Check func(v reflect.Value) string
+
+// Required 不能为零值
+func Required() Check {
+	return func(v reflect.Value) string {
+		if !v.IsValid() || v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+func length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// MinLen 字符串(按字符)、切片或 map 的最小长度
+func MinLen(n int) Check {
+	return func(v reflect.Value) string {
+		if l, ok := length(v); ok && l < n {
+			return fmt.Sprintf("length must be at least %d", n)
+		}
+		return ""
+	}
+}
+
+// MaxLen 字符串(按字符)、切片或 map 的最大长度
+func MaxLen(n int) Check {
+	return func(v reflect.Value) string {
+		if l, ok := length(v); ok && l > n {
+			return fmt.Sprintf("length must be at most %d", n)
+		}
+		return ""
+	}
+}
+
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// Min 数值的最小值
+func Min(n float64) Check {
+	return func(v reflect.Value) string {
+		if f, ok := number(v); ok && f < n {
+			return fmt.Sprintf("must be greater than or equal to %v", n)
+		}
+		return ""
+	}
+}
+
+// Max 数值的最大值
+func Max(n float64) Check {
+	return func(v reflect.Value) string {
+		if f, ok := number(v); ok && f > n {
+			return fmt.Sprintf("must be less than or equal to %v", n)
+		}
+		return ""
+	}
+}
+
+// Pattern 字符串需要匹配正则
+func Pattern(expr string) Check {
+	re := regexp.MustCompile(expr)
+	return func(v reflect.Value) string {
+		if v.Kind() == reflect.String && !re.MatchString(v.String()) {
+			return fmt.Sprintf("must match %s", expr)
+		}
+		return ""
+	}
+}
+
+// OneOf 字符串只能是给定的值之一
+func OneOf(values ...string) Check {
+	return func(v reflect.Value) string {
+		if v.Kind() != reflect.String {
+			return ""
+		}
+		for _, value := range values {
+			if v.String() == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %v", values)
+	}
+}
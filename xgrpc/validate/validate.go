@@ -0,0 +1,129 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Validator 请求消息实现该接口时，validate 拦截器会在调用 handler 前执行 Validate
+type Validator interface {
+	Validate() error
+}
+
+// FieldViolation 单个字段的校验错误
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Violations 所有字段的校验错误，Validate 方法返回该类型时会按字段转换为 errdetails.BadRequest
+type Violations []FieldViolation
+
+func (v Violations) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, violation := range v {
+		if violation.Field == "" {
+			msgs = append(msgs, violation.Description)
+			continue
+		}
+		msgs = append(msgs, violation.Field+": "+violation.Description)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// FieldRule 字段的声明式校验规则，Field 可以是 Go 字段名、proto 字段名或 json 名，嵌套字段使用 . 分隔
+type FieldRule struct {
+	Field  string
+	Checks []Check
+}
+
+func Field(name string, checks ...Check) FieldRule {
+	return FieldRule{Field: name, Checks: checks}
+}
+
+var rules sync.Map
+
+// Register 为消息类型注册声明式校验规则，msg 为该类型的零值，如 (*pb.CreateUserRequest)(nil)
+func Register(msg interface{}, fieldRules ...FieldRule) {
+	typ := reflect.TypeOf(msg)
+	if existing, ok := rules.Load(typ); ok {
+		fieldRules = append(existing.([]FieldRule), fieldRules...)
+	}
+	rules.Store(typ, fieldRules)
+}
+
+// Validate 执行注册的声明式规则和消息自身的 Validate 方法，返回 Violations 或 nil
+func Validate(msg interface{}) error {
+	var violations Violations
+	if fieldRules, ok := rules.Load(reflect.TypeOf(msg)); ok {
+		for _, rule := range fieldRules.([]FieldRule) {
+			value, err := lookup(reflect.ValueOf(msg), rule.Field)
+			if err != nil {
+				violations = append(violations, FieldViolation{Field: rule.Field, Description: err.Error()})
+				continue
+			}
+			for _, check := range rule.Checks {
+				if desc := check(value); desc != "" {
+					violations = append(violations, FieldViolation{Field: rule.Field, Description: desc})
+					break
+				}
+			}
+		}
+	}
+	if v, ok := msg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var vs Violations
+			if errors.As(err, &vs) {
+				violations = append(violations, vs...)
+			} else {
+				violations = append(violations, FieldViolation{Description: err.Error()})
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return violations
+}
+
+// lookup 按字段路径取值，中间的 nil 指针返回零值
+func lookup(v reflect.Value, path string) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("field %s not found", name)
+		}
+		field, ok := fieldByName(v.Type(), name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("field %s not found", name)
+		}
+		v = v.FieldByIndex(field.Index)
+	}
+	return v, nil
+}
+
+func fieldByName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	if f, ok := typ.FieldByName(name); ok {
+		return f, true
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if strings.Split(f.Tag.Get("json"), ",")[0] == name {
+			return f, true
+		}
+		for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+			if part == "name="+name || part == "json="+name {
+				return f, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
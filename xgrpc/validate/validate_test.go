@@ -0,0 +1,66 @@
+package validate
+
+import (
+	"errors"
+	"google.golang.org/grpc/examples/helloworld/helloworld"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type user struct {
+	Name    string `json:"name"`
+	Age     int    `json:"age"`
+	Role    string `json:"role"`
+	Address *address
+}
+
+func (u *user) Validate() error {
+	if u.Role == "admin" && u.Age < 30 {
+		return errors.New("admin must be at least 30")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	Register((*user)(nil),
+		Field("name", Required(), MaxLen(4)),
+		Field("age", Min(18), Max(120)),
+		Field("role", OneOf("admin", "member")),
+		Field("Address.city", Required()),
+	)
+
+	if err := Validate(&user{Name: "tom", Age: 40, Role: "admin", Address: &address{City: "cd"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := Validate(&user{Name: "jerry", Age: 20, Role: "admin"})
+	var violations Violations
+	if !errors.As(err, &violations) {
+		t.Fatalf("want Violations, got %v", err)
+	}
+	want := []string{"name", "Address.city", ""}
+	if len(violations) != len(want) {
+		t.Fatalf("want %d violations, got %v", len(want), violations)
+	}
+	for i, field := range want {
+		if violations[i].Field != field {
+			t.Fatalf("violation %d: want field %q, got %q", i, field, violations[i].Field)
+		}
+	}
+}
+
+func TestValidateProtoName(t *testing.T) {
+	Register((*helloworld.HelloRequest)(nil), Field("name", Required(), Pattern(`^[a-z]+$`)))
+	if err := Validate(&helloworld.HelloRequest{Name: "world"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Validate(&helloworld.HelloRequest{Name: "World"}); err == nil {
+		t.Fatal("want pattern violation")
+	}
+	if err := Validate(&helloworld.HelloReply{}); err != nil {
+		t.Fatalf("unregistered message should pass: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package xclient
+
+import (
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/balancer/round_robin"
+	"github.com/coder2z/g-server/xgrpc/breaker"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"time"
+)
+
+type Config struct {
+	Target   string `mapStructure:"target"`   // etcd://namespaces/name, k8s://namespaces/name, direct://namespaces///127.0.0.1:8000
+	Balancer string `mapStructure:"balancer"` // p2c_x, consistent_hash_x, least_connection_x, random_x, round_robin_x
+
+	DialTimeout time.Duration `mapStructure:"dial_timeout"`
+	Block       bool          `mapStructure:"block"` // 是否阻塞直到连接建立
+
+	KeepaliveTime                time.Duration `mapStructure:"keepalive_time"`
+	KeepaliveTimeout             time.Duration `mapStructure:"keepalive_timeout"`
+	KeepalivePermitWithoutStream bool          `mapStructure:"keepalive_permit_without_stream"`
+
+	CAFile     string `mapStructure:"ca_file"` // 配置后使用 TLS 连接
+	CertFile   string `mapStructure:"cert_file"`
+	KeyFile    string `mapStructure:"key_file"`
+	ServerName string `mapStructure:"server_name"`
+
+	Timeout            time.Duration            `mapStructure:"timeout"`             // timeout, deadline 拦截器使用的默认超时
+	MethodTimeouts     []deadline.MethodTimeout `mapStructure:"method_timeouts"`     // deadline 拦截器按方法覆盖的默认超时
+	SlowThreshold      time.Duration            `mapStructure:"slow_threshold"`      // 慢请求阈值
+	UnaryInterceptors  []string                 `mapStructure:"unary_interceptors"`  // 按顺序启用的 unary 拦截器
+	StreamInterceptors []string                 `mapStructure:"stream_interceptors"` // 按顺序启用的 stream 拦截器
+
+	Breaker *breaker.GroupConfig `mapStructure:"breaker"` // breaker 拦截器配置，可按 FullMethod 覆盖
+	Retry   *retry.Config        `mapStructure:"retry"`   // retry 拦截器配置
+	Hedging *retry.HedgingConfig `mapStructure:"hedging"` // hedging 拦截器配置
+
+	Credentials *auth.ClientConfig `mapStructure:"credentials"` // credentials 拦截器附加的凭证
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Balancer:           round_robin.RoundRobin,
+		DialTimeout:        3 * time.Second,
+		Block:              true,
+		KeepaliveTime:      5 * time.Minute,
+		KeepaliveTimeout:   20 * time.Second,
+		Timeout:            5 * time.Second,
+		SlowThreshold:      time.Second,
+		UnaryInterceptors:  []string{"aid", "deadline", "timeout", "trace", "prometheus", "logger"},
+		StreamInterceptors: []string{"aid", "deadline", "trace", "prometheus", "logger"},
+		Breaker:            breaker.DefaultGroupConfig(),
+		Retry:              retry.DefaultConfig(),
+		Hedging:            retry.DefaultHedgingConfig(),
+	}
+}
@@ -0,0 +1,170 @@
+package xclient
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/breaker"
+	clientinterceptors "github.com/coder2z/g-server/xgrpc/client"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"google.golang.org/grpc"
+	"sync"
+)
+
+type (
+	UnaryInterceptorBuilder  func(name string, c *Config) grpc.UnaryClientInterceptor
+	StreamInterceptorBuilder func(name string, c *Config) grpc.StreamClientInterceptor
+)
+
+var (
+	unaryBuilders  sync.Map
+	streamBuilders sync.Map
+
+	breakersMu sync.Mutex
+	breakers   = make(map[*Config]*breaker.Group)
+)
+
+// breakerGroup 同一个连接的 unary 和 stream 拦截器共用一个熔断器组，连接关闭时由 closeBreakers 关闭
+func breakerGroup(c *Config) *breaker.Group {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	g, ok := breakers[c]
+	if !ok {
+		g = breaker.NewGroup(c.Breaker)
+		breakers[c] = g
+	}
+	return g
+}
+
+func closeBreakers(c *Config) {
+	breakersMu.Lock()
+	g, ok := breakers[c]
+	delete(breakers, c)
+	breakersMu.Unlock()
+	if ok {
+		g.Close()
+	}
+}
+
+func init() {
+	RegisterUnaryInterceptor("aid", func(string, *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.XAidUnaryClientInterceptor()
+	})
+	RegisterUnaryInterceptor("timeout", func(_ string, c *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.XTimeoutUnaryClientInterceptor(c.Timeout, c.SlowThreshold)
+	})
+	RegisterUnaryInterceptor("deadline", func(_ string, c *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.XDeadlineUnaryClientInterceptor(deadline.Timeouts{Default: c.Timeout, Methods: c.MethodTimeouts})
+	})
+	RegisterUnaryInterceptor("trace", func(string, *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.XTraceUnaryClientInterceptor()
+	})
+	RegisterUnaryInterceptor("prometheus", func(name string, _ *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.PrometheusUnaryClientInterceptor(name)
+	})
+	RegisterUnaryInterceptor("logger", func(name string, _ *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.XLoggerUnaryClientInterceptor(name)
+	})
+	RegisterUnaryInterceptor("breaker", func(_ string, c *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.BreakerUnaryClientInterceptor(breakerGroup(c))
+	})
+	RegisterUnaryInterceptor("retry", func(name string, c *Config) grpc.UnaryClientInterceptor {
+		policy, err := retry.New(c.Retry)
+		if err != nil {
+			xlog.Panic("Application Starting",
+				xlog.FieldComponentName("XInvoker"),
+				xlog.FieldMethod("XInvoker.XClient.Retry"),
+				xlog.FieldDescription(fmt.Sprintf("grpc client(%s) retry config error", name)),
+				xlog.FieldErr(err),
+			)
+		}
+		return clientinterceptors.RetryUnaryClientInterceptor(name, policy)
+	})
+	RegisterUnaryInterceptor("hedging", func(name string, c *Config) grpc.UnaryClientInterceptor {
+		hedger, err := retry.NewHedger(c.Hedging)
+		if err != nil {
+			xlog.Panic("Application Starting",
+				xlog.FieldComponentName("XInvoker"),
+				xlog.FieldMethod("XInvoker.XClient.Hedging"),
+				xlog.FieldDescription(fmt.Sprintf("grpc client(%s) hedging config error", name)),
+				xlog.FieldErr(err),
+			)
+		}
+		return clientinterceptors.HedgingUnaryClientInterceptor(name, hedger)
+	})
+	RegisterUnaryInterceptor("credentials", func(name string, c *Config) grpc.UnaryClientInterceptor {
+		return clientinterceptors.CredentialsUnaryClientInterceptor(c.clientCredentials(name))
+	})
+
+	RegisterStreamInterceptor("aid", func(string, *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.XAidStreamClientInterceptor()
+	})
+	RegisterStreamInterceptor("timeout", func(_ string, c *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.XTimeoutStreamClientInterceptor(c.Timeout, c.SlowThreshold)
+	})
+	RegisterStreamInterceptor("deadline", func(string, *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.XDeadlineStreamClientInterceptor()
+	})
+	RegisterStreamInterceptor("trace", func(string, *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.XTraceStreamClientInterceptor()
+	})
+	RegisterStreamInterceptor("prometheus", func(name string, _ *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.PrometheusStreamClientInterceptor(name)
+	})
+	RegisterStreamInterceptor("logger", func(name string, _ *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.XLoggerStreamClientInterceptor(name)
+	})
+	RegisterStreamInterceptor("breaker", func(_ string, c *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.BreakerStreamClientInterceptor(breakerGroup(c))
+	})
+	RegisterStreamInterceptor("credentials", func(name string, c *Config) grpc.StreamClientInterceptor {
+		return clientinterceptors.CredentialsStreamClientInterceptor(c.clientCredentials(name))
+	})
+}
+
+// RegisterUnaryInterceptor 注册可以在配置中按名称启用的 unary 拦截器
+func RegisterUnaryInterceptor(name string, builder UnaryInterceptorBuilder) {
+	unaryBuilders.Store(name, builder)
+}
+
+// RegisterStreamInterceptor 注册可以在配置中按名称启用的 stream 拦截器
+func RegisterStreamInterceptor(name string, builder StreamInterceptorBuilder) {
+	streamBuilders.Store(name, builder)
+}
+
+func (config *Config) clientCredentials(name string) auth.Credentials {
+	creds := auth.NewCredentials(config.Credentials)
+	if creds == nil {
+		xlog.Panic("Application Starting",
+			xlog.FieldComponentName("XInvoker"),
+			xlog.FieldMethod("XInvoker.XClient.Credentials"),
+			xlog.FieldDescription(fmt.Sprintf("grpc client(%s) credentials not configured", name)),
+		)
+	}
+	return creds
+}
+
+func (config *Config) unaryInterceptors(name string) ([]grpc.UnaryClientInterceptor, error) {
+	interceptors := make([]grpc.UnaryClientInterceptor, 0, len(config.UnaryInterceptors))
+	for _, n := range config.UnaryInterceptors {
+		builder, ok := unaryBuilders.Load(n)
+		if !ok {
+			return nil, fmt.Errorf("unary interceptor %s not registered", n)
+		}
+		interceptors = append(interceptors, builder.(UnaryInterceptorBuilder)(name, config))
+	}
+	return interceptors, nil
+}
+
+func (config *Config) streamInterceptors(name string) ([]grpc.StreamClientInterceptor, error) {
+	interceptors := make([]grpc.StreamClientInterceptor, 0, len(config.StreamInterceptors))
+	for _, n := range config.StreamInterceptors {
+		builder, ok := streamBuilders.Load(n)
+		if !ok {
+			return nil, fmt.Errorf("stream interceptor %s not registered", n)
+		}
+		interceptors = append(interceptors, builder.(StreamInterceptorBuilder)(name, config))
+	}
+	return interceptors, nil
+}
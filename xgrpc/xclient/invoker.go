@@ -0,0 +1,128 @@
+package xclient
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xinvoker"
+	"google.golang.org/grpc"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// drainTimeout 热更新替换或删除连接后，等待进行中的请求结束再关闭旧连接
+var drainTimeout = 30 * time.Second
+
+var clientI *clientInvoker
+
+// Register 注册 gRPC 客户端，k 为配置前缀，例如 grpc.client
+func Register(k string) xinvoker.Invoker {
+	clientI = &clientInvoker{key: k}
+	return clientI
+}
+
+// Invoker 获取 [grpc.client.name] 对应的连接
+func Invoker(name string) *grpc.ClientConn {
+	if val, ok := clientI.instances.Load(name); ok {
+		return val.(*client).conn
+	}
+	xlog.Panic("Application Starting",
+		xlog.FieldComponentName("XInvoker"),
+		xlog.FieldMethod("XInvoker.XClient"),
+		xlog.FieldDescription(fmt.Sprintf("no grpc client(%s) invoker found", name)),
+	)
+	return nil
+}
+
+type client struct {
+	conn   *grpc.ClientConn
+	config *Config
+}
+
+type clientInvoker struct {
+	xinvoker.Base
+	instances sync.Map
+	key       string
+}
+
+func (i *clientInvoker) Init(opts ...xinvoker.Option) error {
+	i.instances = sync.Map{}
+	for name, cfg := range i.loadConfig() {
+		c, err := i.newClient(name, cfg)
+		if err != nil {
+			xlog.Panic("Application Starting",
+				xlog.FieldComponentName("XInvoker"),
+				xlog.FieldMethod("XInvoker.XClient.NewClient"),
+				xlog.FieldDescription(fmt.Sprintf("New grpc client(%s) error", name)),
+				xlog.FieldAddr(cfg.Target),
+				xlog.FieldErr(err),
+			)
+		}
+		i.instances.Store(name, c)
+	}
+	return nil
+}
+
+// Reload 仅重建配置发生变化的连接，新配置建立连接失败时保留旧连接；
+// 被替换或已从配置中删除的连接在 drainTimeout 后关闭
+func (i *clientInvoker) Reload(opts ...xinvoker.Option) error {
+	configs := i.loadConfig()
+	for name, cfg := range configs {
+		old, loaded := i.instances.Load(name)
+		if loaded && reflect.DeepEqual(old.(*client).config, cfg) {
+			continue
+		}
+		c, err := i.reloadClient(name, cfg)
+		if err != nil {
+			xlog.Error("Application Reload",
+				xlog.FieldComponentName("XInvoker"),
+				xlog.FieldMethod("XInvoker.XClient.Reload"),
+				xlog.FieldDescription(fmt.Sprintf("Reload grpc client(%s) error, keep previous connection", name)),
+				xlog.FieldAddr(cfg.Target),
+				xlog.FieldErr(err),
+			)
+			continue
+		}
+		i.instances.Store(name, c)
+		if loaded {
+			drain(old.(*client))
+		}
+	}
+	i.instances.Range(func(key, value interface{}) bool {
+		if _, ok := configs[key.(string)]; !ok {
+			i.instances.Delete(key)
+			drain(value.(*client))
+		}
+		return true
+	})
+	return nil
+}
+
+// reloadClient 拦截器构造时的 panic 同样视为配置错误
+func (i *clientInvoker) reloadClient(name string, cfg *Config) (c *client, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			closeBreakers(cfg)
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return i.newClient(name, cfg)
+}
+
+func drain(c *client) {
+	time.AfterFunc(drainTimeout, c.close)
+}
+
+func (c *client) close() {
+	_ = c.conn.Close()
+	closeBreakers(c.config)
+}
+
+func (i *clientInvoker) Close(opts ...xinvoker.Option) error {
+	i.instances.Range(func(key, value interface{}) bool {
+		value.(*client).close()
+		i.instances.Delete(key)
+		return true
+	})
+	return nil
+}
@@ -0,0 +1,113 @@
+package xclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-server/xgrpc"
+	_ "github.com/coder2z/g-server/xgrpc/balancer/consistent_hash"
+	_ "github.com/coder2z/g-server/xgrpc/balancer/least_connection"
+	_ "github.com/coder2z/g-server/xgrpc/balancer/p2c"
+	_ "github.com/coder2z/g-server/xgrpc/balancer/random"
+	_ "github.com/coder2z/g-server/xgrpc/balancer/round_robin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"io/ioutil"
+)
+
+// Dial 按配置建立连接，name 用于监控和日志
+// etcd/k8s/direct 等 target 需要先调用对应 xregistry 的 RegisterBuilder
+func (config *Config) Dial(name string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	options, err := config.dialOptions(name)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, opts...)
+
+	ctx := context.Background()
+	if config.Block && config.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.DialTimeout)
+		defer cancel()
+	}
+	return grpc.DialContext(ctx, config.Target, options...)
+}
+
+func (config *Config) dialOptions(name string) ([]grpc.DialOption, error) {
+	unary, err := config.unaryInterceptors(name)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := config.streamInterceptors(name)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"LoadBalancingPolicy": "%s"}`, config.Balancer)),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                config.KeepaliveTime,
+			Timeout:             config.KeepaliveTimeout,
+			PermitWithoutStream: config.KeepalivePermitWithoutStream,
+		}),
+		xgrpc.WithUnaryClientInterceptors(unary...),
+		xgrpc.WithStreamClientInterceptors(stream...),
+	}
+	if config.Block {
+		options = append(options, grpc.WithBlock())
+	}
+
+	if config.CAFile == "" {
+		options = append(options, grpc.WithInsecure())
+	} else {
+		creds, err := config.credentials()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.WithTransportCredentials(creds))
+	}
+	return options, nil
+}
+
+func (config *Config) credentials() (credentials.TransportCredentials, error) {
+	ca, err := ioutil.ReadFile(config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("append ca failed")
+	}
+	tlsConfig := &tls.Config{RootCAs: pool, ServerName: config.ServerName}
+	if config.CertFile != "" && config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func (i *clientInvoker) newClient(name string, o *Config) (*client, error) {
+	conn, err := o.Dial(name)
+	if err != nil {
+		closeBreakers(o)
+		return nil, err
+	}
+	return &client{conn: conn, config: o}, nil
+}
+
+func (i *clientInvoker) loadConfig() map[string]*Config {
+	conf := make(map[string]*Config)
+	prefix := i.key
+	for name := range xcfg.GetStringMap(prefix) {
+		cfg := xcfg.UnmarshalWithExpect(prefix+"."+name, DefaultConfig()).(*Config)
+		conf[name] = cfg
+	}
+	return conf
+}
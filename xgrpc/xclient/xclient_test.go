@@ -0,0 +1,106 @@
+package xclient
+
+import (
+	"context"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-server/xgrpc/xserver"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"testing"
+	"time"
+)
+
+func TestClientInvoker(t *testing.T) {
+	c := xserver.DefaultConfig()
+	xserver.WithHost("127.0.0.1")(c)
+	xserver.WithPort(0)(c)
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = s.Serve()
+	}()
+	defer s.Stop()
+
+	_ = xcfg.Apply(map[string]interface{}{
+		"grpc": map[string]interface{}{
+			"client": map[string]interface{}{
+				"user": map[string]interface{}{
+					"target":       s.Address(),
+					"dial_timeout": "1s",
+				},
+			},
+		},
+	})
+
+	invoker := Register("grpc.client")
+	if err := invoker.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer invoker.Close()
+
+	conn := Invoker("user")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v", resp.Status)
+	}
+
+	// 配置未变化时不重建连接
+	if err := invoker.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if Invoker("user") != conn {
+		t.Fatal("connection rebuilt without config change")
+	}
+
+	defer func(d time.Duration) { drainTimeout = d }(drainTimeout)
+	drainTimeout = 50 * time.Millisecond
+
+	// 新配置错误时保留旧连接
+	_ = xcfg.Apply(map[string]interface{}{
+		"grpc": map[string]interface{}{
+			"client": map[string]interface{}{
+				"user": map[string]interface{}{"unary_interceptors": []interface{}{"not-registered"}},
+			},
+		},
+	})
+	if err := invoker.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if Invoker("user") != conn || conn.GetState() == connectivity.Shutdown {
+		t.Fatal("connection should be kept when reload failed")
+	}
+
+	// 配置中已删除的连接在 drainTimeout 后关闭
+	removed := &client{conn: conn, config: DefaultConfig()}
+	clientI.instances.Store("removed", removed)
+	_ = xcfg.Apply(map[string]interface{}{
+		"grpc": map[string]interface{}{
+			"client": map[string]interface{}{
+				"user": map[string]interface{}{"unary_interceptors": []interface{}{"aid", "timeout"}},
+			},
+		},
+	})
+	if err := invoker.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := clientI.instances.Load("removed"); ok {
+		t.Fatal("removed client should be deleted")
+	}
+	if Invoker("user") == conn {
+		t.Fatal("connection should be rebuilt after config change")
+	}
+	if conn.GetState() == connectivity.Shutdown {
+		t.Fatal("old connection should stay open until drain timeout")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if conn.GetState() != connectivity.Shutdown {
+		t.Fatal("old connection should be closed")
+	}
+}
@@ -0,0 +1,185 @@
+package xserver
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xdefer"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xcode/i18n"
+	"github.com/coder2z/g-server/xgrpc/auth"
+	"github.com/coder2z/g-server/xgrpc/authz"
+	"github.com/coder2z/g-server/xgrpc/ratelimit"
+	serverinterceptors "github.com/coder2z/g-server/xgrpc/server"
+	"github.com/coder2z/g-server/xgrpc/shedding"
+	"time"
+)
+
+type Config struct {
+	Host    string `mapStructure:"host"`
+	Port    int    `mapStructure:"port"`
+	Network string `mapStructure:"network"`
+
+	MaxRecvMsgSize int `mapStructure:"max_recv_msg_size"` // 单个请求最大字节数，默认4M
+	MaxSendMsgSize int `mapStructure:"max_send_msg_size"` // 单个响应最大字节数，默认4M
+
+	KeepaliveMinTime             time.Duration `mapStructure:"keepalive_min_time"`              // 客户端 ping 的最小间隔
+	KeepalivePermitWithoutStream bool          `mapStructure:"keepalive_permit_without_stream"` // 没有活跃流时是否允许 ping
+	MaxConnectionIdle            time.Duration `mapStructure:"max_connection_idle"`
+	MaxConnectionAge             time.Duration `mapStructure:"max_connection_age"`
+	MaxConnectionAgeGrace        time.Duration `mapStructure:"max_connection_age_grace"`
+	KeepaliveTime                time.Duration `mapStructure:"keepalive_time"`
+	KeepaliveTimeout             time.Duration `mapStructure:"keepalive_timeout"`
+
+	CertFile     string `mapStructure:"cert_file"`
+	KeyFile      string `mapStructure:"key_file"`
+	ClientCAFile string `mapStructure:"client_ca_file"` // 配置后开启双向认证
+
+	Timeout            time.Duration `mapStructure:"timeout"`             // timeout, deadline 拦截器使用的默认超时
+	DeadlineReserve    time.Duration `mapStructure:"deadline_reserve"`    // deadline 拦截器从上游预算中扣除的网络耗时
+	UnaryInterceptors  []string      `mapStructure:"unary_interceptors"`  // 按顺序启用的 unary 拦截器
+	StreamInterceptors []string      `mapStructure:"stream_interceptors"` // 按顺序启用的 stream 拦截器
+
+	Logger   *serverinterceptors.LoggerConfig `mapStructure:"logger"`   // logger 拦截器配置
+	Shedding *shedding.Config                 `mapStructure:"shedding"` // shedding 拦截器配置
+	Auth     *auth.Config                     `mapStructure:"auth"`     // auth 拦截器配置
+	I18n     *i18n.Config                     `mapStructure:"i18n"`     // i18n 拦截器配置
+
+	key            string
+	rateLimiter    *ratelimit.Manager
+	shedder        *shedding.Shedder
+	verifier       auth.Verifier
+	authorizer     *authz.Engine
+	localeResolver i18n.Resolver
+}
+
+type Option func(c *Config)
+
+func DefaultConfig() *Config {
+	return &Config{
+		Host:                         xapp.HostIP(),
+		Port:                         9090,
+		Network:                      "tcp",
+		MaxRecvMsgSize:               1024 * 1024 * 4,
+		MaxSendMsgSize:               1024 * 1024 * 4,
+		KeepaliveMinTime:             5 * time.Minute,
+		KeepalivePermitWithoutStream: false,
+		Timeout:                      5 * time.Second,
+		DeadlineReserve:              10 * time.Millisecond,
+		UnaryInterceptors:            []string{"crash", "prometheus", "trace", "logger", "deadline"},
+		StreamInterceptors:           []string{"crash", "prometheus", "trace", "logger", "deadline"},
+		Logger:                       serverinterceptors.DefaultLoggerConfig(),
+		Shedding:                     shedding.DefaultConfig(),
+		Auth:                         auth.DefaultConfig(),
+		I18n:                         i18n.DefaultConfig(),
+		key:                          "app.grpc",
+	}
+}
+
+// RawConfig 读取 key 下的配置
+func RawConfig(key string) *Config {
+	config := xcfg.UnmarshalWithExpect(key, DefaultConfig()).(*Config)
+	config.key = key
+	return config
+}
+
+// StdConfig 读取 app.grpc 下的配置
+func StdConfig() *Config {
+	return RawConfig("app.grpc")
+}
+
+func (config Config) Address() string {
+	return fmt.Sprintf("%s:%d", config.Host, config.Port)
+}
+
+// RateLimiter ratelimit 拦截器使用的限流规则，读取 {key}.ratelimit 并热更新
+func (config *Config) RateLimiter() *ratelimit.Manager {
+	if config.rateLimiter == nil {
+		config.rateLimiter = ratelimit.New(config.key + ".ratelimit")
+	}
+	return config.rateLimiter
+}
+
+// Shedder shedding 拦截器使用的降载器，以配置 key 命名
+func (config *Config) Shedder() *shedding.Shedder {
+	if config.shedder == nil {
+		config.shedder = shedding.New(config.key, config.Shedding)
+	}
+	return config.shedder
+}
+
+// Authorizer authz 拦截器使用的授权规则，读取 {key}.authz 并热更新
+func (config *Config) Authorizer() *authz.Engine {
+	if config.authorizer == nil {
+		config.authorizer = authz.New(config.key + ".authz")
+	}
+	return config.authorizer
+}
+
+// Verifier auth 拦截器使用的认证方式
+func (config *Config) Verifier() auth.Verifier {
+	if config.verifier == nil {
+		verifier, err := auth.New(config.Auth)
+		if err != nil {
+			xlog.Panic("Application Starting",
+				xlog.FieldComponentName("XGrpc"),
+				xlog.FieldMethod("XGrpc.XServer.Verifier"),
+				xlog.FieldDescription("gRPC server auth config error"),
+				xlog.FieldErr(err),
+			)
+		}
+		config.verifier = verifier
+	}
+	return config.verifier
+}
+
+// LocaleResolver i18n 拦截器使用的 locale 解析，首次调用时加载配置的翻译包
+func (config *Config) LocaleResolver() i18n.Resolver {
+	if config.localeResolver == nil {
+		if err := config.I18n.Load(); err != nil {
+			xlog.Panic("Application Starting",
+				xlog.FieldComponentName("XGrpc"),
+				xlog.FieldMethod("XGrpc.XServer.LocaleResolver"),
+				xlog.FieldDescription("gRPC server i18n bundle error"),
+				xlog.FieldErr(err),
+			)
+		}
+		xdefer.Register(config.I18n.Close)
+		config.localeResolver = config.I18n.Resolver()
+	}
+	return config.localeResolver
+}
+
+func (config Config) tls() bool {
+	return config.CertFile != "" && config.KeyFile != ""
+}
+
+func WithNetwork(network string) Option {
+	return func(c *Config) {
+		c.Network = network
+	}
+}
+
+func WithHost(host string) Option {
+	return func(c *Config) {
+		c.Host = host
+	}
+}
+
+func WithPort(port int) Option {
+	return func(c *Config) {
+		c.Port = port
+	}
+}
+
+func WithUnaryInterceptors(names ...string) Option {
+	return func(c *Config) {
+		c.UnaryInterceptors = names
+	}
+}
+
+func WithStreamInterceptors(names ...string) Option {
+	return func(c *Config) {
+		c.StreamInterceptors = names
+	}
+}
@@ -0,0 +1,125 @@
+package xserver
+
+import (
+	"fmt"
+	serverinterceptors "github.com/coder2z/g-server/xgrpc/server"
+	"google.golang.org/grpc"
+	"sync"
+)
+
+type (
+	UnaryInterceptorBuilder  func(c *Config) grpc.UnaryServerInterceptor
+	StreamInterceptorBuilder func(c *Config) grpc.StreamServerInterceptor
+)
+
+var (
+	unaryBuilders  sync.Map
+	streamBuilders sync.Map
+)
+
+func init() {
+	RegisterUnaryInterceptor("crash", func(*Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.CrashUnaryServerInterceptor()
+	})
+	RegisterUnaryInterceptor("prometheus", func(*Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.PrometheusUnaryServerInterceptor()
+	})
+	RegisterUnaryInterceptor("trace", func(*Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.TraceUnaryServerInterceptor()
+	})
+	RegisterUnaryInterceptor("logger", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.XLoggerUnaryServerInterceptor(c.Logger)
+	})
+	RegisterUnaryInterceptor("ratelimit", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.RateLimitUnaryServerInterceptor(c.RateLimiter())
+	})
+	RegisterUnaryInterceptor("shedding", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.SheddingUnaryServerInterceptor(c.Shedder())
+	})
+	RegisterUnaryInterceptor("auth", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.AuthUnaryServerInterceptor(c.Auth, c.Verifier())
+	})
+	RegisterUnaryInterceptor("authz", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.AuthzUnaryServerInterceptor(c.Authorizer())
+	})
+	RegisterUnaryInterceptor("validate", func(*Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.ValidateUnaryServerInterceptor()
+	})
+	RegisterUnaryInterceptor("i18n", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.I18nUnaryServerInterceptor(c.LocaleResolver())
+	})
+	RegisterUnaryInterceptor("timeout", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.XTimeoutUnaryServerInterceptor(c.Timeout)
+	})
+	RegisterUnaryInterceptor("deadline", func(c *Config) grpc.UnaryServerInterceptor {
+		return serverinterceptors.XDeadlineUnaryServerInterceptor(c.Timeout, c.DeadlineReserve)
+	})
+
+	RegisterStreamInterceptor("crash", func(*Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.CrashStreamServerInterceptor()
+	})
+	RegisterStreamInterceptor("prometheus", func(*Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.PrometheusStreamServerInterceptor()
+	})
+	RegisterStreamInterceptor("trace", func(*Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.TraceStreamServerInterceptor
+	})
+	RegisterStreamInterceptor("logger", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.XLoggerStreamServerInterceptor(c.Logger)
+	})
+	RegisterStreamInterceptor("ratelimit", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.RateLimitStreamServerInterceptor(c.RateLimiter())
+	})
+	RegisterStreamInterceptor("shedding", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.SheddingStreamServerInterceptor(c.Shedder())
+	})
+	RegisterStreamInterceptor("auth", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.AuthStreamServerInterceptor(c.Auth, c.Verifier())
+	})
+	RegisterStreamInterceptor("authz", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.AuthzStreamServerInterceptor(c.Authorizer())
+	})
+	RegisterStreamInterceptor("validate", func(*Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.ValidateStreamServerInterceptor()
+	})
+	RegisterStreamInterceptor("i18n", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.I18nStreamServerInterceptor(c.LocaleResolver())
+	})
+	RegisterStreamInterceptor("deadline", func(c *Config) grpc.StreamServerInterceptor {
+		return serverinterceptors.XDeadlineStreamServerInterceptor(c.DeadlineReserve)
+	})
+}
+
+// RegisterUnaryInterceptor 注册可以在配置中按名称启用的 unary 拦截器
+func RegisterUnaryInterceptor(name string, builder UnaryInterceptorBuilder) {
+	unaryBuilders.Store(name, builder)
+}
+
+// RegisterStreamInterceptor 注册可以在配置中按名称启用的 stream 拦截器
+func RegisterStreamInterceptor(name string, builder StreamInterceptorBuilder) {
+	streamBuilders.Store(name, builder)
+}
+
+func (config *Config) unaryInterceptors() ([]grpc.UnaryServerInterceptor, error) {
+	interceptors := make([]grpc.UnaryServerInterceptor, 0, len(config.UnaryInterceptors))
+	for _, name := range config.UnaryInterceptors {
+		builder, ok := unaryBuilders.Load(name)
+		if !ok {
+			return nil, fmt.Errorf("unary interceptor %s not registered", name)
+		}
+		interceptors = append(interceptors, builder.(UnaryInterceptorBuilder)(config))
+	}
+	return interceptors, nil
+}
+
+func (config *Config) streamInterceptors() ([]grpc.StreamServerInterceptor, error) {
+	interceptors := make([]grpc.StreamServerInterceptor, 0, len(config.StreamInterceptors))
+	for _, name := range config.StreamInterceptors {
+		builder, ok := streamBuilders.Load(name)
+		if !ok {
+			return nil, fmt.Errorf("stream interceptor %s not registered", name)
+		}
+		interceptors = append(interceptors, builder.(StreamInterceptorBuilder)(config))
+	}
+	return interceptors, nil
+}
@@ -0,0 +1,180 @@
+package xserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xgrpc"
+	"github.com/coder2z/g-server/xhealth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"io/ioutil"
+	"net"
+)
+
+type Server struct {
+	*grpc.Server
+	config   *Config
+	listener net.Listener
+	health   *health.Server
+	unwatch  func()
+}
+
+// Build 按配置创建 gRPC 服务并监听端口，opts 追加在配置生成的选项之后
+func (config *Config) Build(opts ...grpc.ServerOption) (*Server, error) {
+	options, err := config.serverOptions()
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, opts...)
+
+	listener, err := net.Listen(config.Network, config.Address())
+	if err != nil {
+		return nil, err
+	}
+	// 端口为0时使用系统分配的端口
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok {
+		config.Port = addr.Port
+	}
+
+	s := &Server{
+		Server:   grpc.NewServer(options...),
+		config:   config,
+		listener: listener,
+		health:   health.NewServer(),
+	}
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	reflection.Register(s.Server)
+	// 依赖未就绪时置为 NOT_SERVING，Shutdown 之后的更新会被忽略
+	s.unwatch = xhealth.Watch(func(ready bool) {
+		if ready {
+			s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		} else {
+			s.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	})
+	return s, nil
+}
+
+func (config *Config) serverOptions() ([]grpc.ServerOption, error) {
+	unary, err := config.unaryInterceptors()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := config.streamInterceptors()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(config.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(config.MaxSendMsgSize),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             config.KeepaliveMinTime,
+			PermitWithoutStream: config.KeepalivePermitWithoutStream,
+		}),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     config.MaxConnectionIdle,
+			MaxConnectionAge:      config.MaxConnectionAge,
+			MaxConnectionAgeGrace: config.MaxConnectionAgeGrace,
+			Time:                  config.KeepaliveTime,
+			Timeout:               config.KeepaliveTimeout,
+		}),
+		xgrpc.WithUnaryServerInterceptors(unary...),
+		xgrpc.WithStreamServerInterceptors(stream...),
+	}
+
+	if config.tls() {
+		creds, err := config.credentials()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+	return options, nil
+}
+
+func (config *Config) credentials() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if config.ClientCAFile != "" {
+		ca, err := ioutil.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("append client ca failed")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// Health 标准 grpc.health.v1 服务，整体状态("")由 xhealth 的 readiness 驱动
+func (s *Server) Health() *health.Server {
+	return s.health
+}
+
+// Config 服务使用的配置
+func (s *Server) Config() *Config {
+	return s.config
+}
+
+// Address 服务地址，用于服务注册
+func (s *Server) Address() string {
+	return s.config.Address()
+}
+
+func (s *Server) Serve() error {
+	xlog.Info("Application Starting",
+		xlog.FieldComponentName("XGrpc"),
+		xlog.FieldMethod("XGrpc.XServer.Serve"),
+		xlog.FieldDescription(fmt.Sprintf("gRPC serve running :%v", s.Address())),
+	)
+	err := s.Server.Serve(s.listener)
+	if err == grpc.ErrServerStopped {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) Stop() error {
+	s.unwatch()
+	s.health.Shutdown()
+	s.Server.Stop()
+	return nil
+}
+
+// GracefulStop 将健康状态置为 NOT_SERVING 并等待处理中的请求完成，ctx 结束时返回 ctx.Err()
+func (s *Server) GracefulStop(ctx context.Context) error {
+	s.unwatch()
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		xlog.Info("Application Stopping",
+			xlog.FieldComponentName("XGrpc"),
+			xlog.FieldMethod("XGrpc.XServer.GracefulStop"),
+			xlog.FieldDescription("gRPC server shutdown"),
+		)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,96 @@
+package xserver
+
+import (
+	"context"
+	"errors"
+	"github.com/coder2z/g-server/xhealth"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	c := DefaultConfig()
+	WithHost("127.0.0.1")(c)
+	WithPort(0)(c)
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = s.Serve()
+	}()
+
+	conn, err := grpc.Dial(s.Address(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v", resp.Status)
+	}
+
+	if err := s.GracefulStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerHealthProbe(t *testing.T) {
+	c := DefaultConfig()
+	WithHost("127.0.0.1")(c)
+	WithPort(0)(c)
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = s.Serve()
+	}()
+	defer s.Stop()
+
+	conn, err := grpc.Dial(s.Address(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	check := func() healthpb.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+
+	xhealth.Register("xserver.test", func(ctx context.Context) error {
+		return errors.New("down")
+	}, xhealth.WithInterval(time.Hour))
+	if status := check(); status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v", status)
+	}
+	xhealth.Deregister("xserver.test")
+	if status := check(); status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v", status)
+	}
+}
+
+func TestServerUnknownInterceptor(t *testing.T) {
+	c := DefaultConfig()
+	WithHost("127.0.0.1")(c)
+	WithPort(0)(c)
+	WithUnaryInterceptors("unknown")(c)
+	if _, err := c.Build(); err == nil {
+		t.Fatal("expect unknown interceptor error")
+	}
+}
@@ -0,0 +1,365 @@
+package xhealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xmonitor"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp      = "UP"
+	StatusDown    = "DOWN"
+	StatusUnknown = "UNKNOWN" // 注册后尚未完成第一次检查
+
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 3 * time.Second
+)
+
+// Probe 检查一个依赖是否可用，应在 ctx 结束前返回
+type Probe func(ctx context.Context) error
+
+type Option func(p *probe)
+
+// WithInterval 检查间隔，默认 10s，不大于 0 时使用默认值
+func WithInterval(d time.Duration) Option {
+	return func(p *probe) {
+		p.interval = d
+	}
+}
+
+// WithTimeout 单次检查超时，默认 3s，不大于 0 时使用默认值
+func WithTimeout(d time.Duration) Option {
+	return func(p *probe) {
+		p.timeout = d
+	}
+}
+
+// WithFailureThreshold 连续失败多少次后置为 DOWN，默认 1，避免偶发失败导致实例被摘除
+func WithFailureThreshold(n int) Option {
+	return func(p *probe) {
+		p.threshold = n
+	}
+}
+
+// NonCritical 失败时只在结果中展示，不影响 readiness，适用于短信、邮件等非核心依赖
+func NonCritical() Option {
+	return func(p *probe) {
+		p.critical = false
+	}
+}
+
+// Liveness 失败时同时影响 liveness，只应用于进程无法自行恢复的情况，如死锁检测
+func Liveness() Option {
+	return func(p *probe) {
+		p.liveness = true
+	}
+}
+
+// Result 单个探针最近一次检查结果
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Liveness  bool      `json:"liveness"`
+	Error     string    `json:"error,omitempty"`
+	Latency   string    `json:"latency,omitempty"`
+	Failures  int       `json:"failures,omitempty"` // 连续失败次数
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+}
+
+// Report 汇总结果，Checks 按名称排序
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Up 汇总状态是否为 UP
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type probe struct {
+	name      string
+	fn        Probe
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+	critical  bool
+	liveness  bool
+	done      chan struct{}
+
+	mu     sync.Mutex
+	result Result
+}
+
+// Checker 定时执行已注册的探针，汇总为 liveness 和 readiness
+type Checker struct {
+	mu     sync.RWMutex
+	probes map[string]*probe
+
+	watchMu  sync.Mutex
+	watchers map[int]func(ready bool)
+	watchSeq int
+	ready    *bool
+}
+
+func New() *Checker {
+	return &Checker{
+		probes:   make(map[string]*probe),
+		watchers: make(map[int]func(ready bool)),
+	}
+}
+
+var defaultChecker = New()
+
+// Default 全局 Checker，invoker 和 xgovern、xserver 都使用它
+func Default() *Checker {
+	return defaultChecker
+}
+
+// Register 注册探针并立即开始检查，同名探针会被替换
+func Register(name string, fn Probe, opts ...Option) {
+	defaultChecker.Register(name, fn, opts...)
+}
+
+// Deregister 停止并移除探针
+func Deregister(name string) {
+	defaultChecker.Deregister(name)
+}
+
+// RegisterInvoker 注册 invoker 实例的探针，探针名称为 {key}.{name}
+func RegisterInvoker(key, name string, fn Probe, opts ...Option) {
+	Register(key+"."+name, fn, opts...)
+}
+
+// DeregisterInvoker 移除 RegisterInvoker 注册的探针
+func DeregisterInvoker(key, name string) {
+	Deregister(key + "." + name)
+}
+
+// Live 全局 liveness
+func Live() Report {
+	return defaultChecker.Live()
+}
+
+// Ready 全局 readiness
+func Ready() Report {
+	return defaultChecker.Ready()
+}
+
+// Check 立即执行一次全局 Checker 的所有探针
+func Check() {
+	defaultChecker.Check()
+}
+
+// Watch 订阅全局 readiness 变化
+func Watch(fn func(ready bool)) (cancel func()) {
+	return defaultChecker.Watch(fn)
+}
+
+func (c *Checker) Register(name string, fn Probe, opts ...Option) {
+	p := &probe{
+		name:      name,
+		fn:        fn,
+		interval:  defaultInterval,
+		timeout:   defaultTimeout,
+		threshold: 1,
+		critical:  true,
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.threshold < 1 {
+		p.threshold = 1
+	}
+	if p.interval <= 0 {
+		p.interval = defaultInterval
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultTimeout
+	}
+	p.result = Result{Name: name, Status: StatusUnknown, Critical: p.critical, Liveness: p.liveness}
+
+	c.mu.Lock()
+	old := c.probes[name]
+	c.probes[name] = p
+	c.mu.Unlock()
+	if old != nil {
+		close(old.done)
+	}
+
+	go c.run(p)
+	c.notify()
+}
+
+func (c *Checker) Deregister(name string) {
+	c.mu.Lock()
+	p, ok := c.probes[name]
+	delete(c.probes, name)
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	close(p.done)
+	xmonitor.HealthProbeGauge.DeleteLabelValues(name)
+	c.notify()
+}
+
+func (c *Checker) run(p *probe) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		c.check(p)
+		c.notify()
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Check 并发执行一次所有探针并等待结果，用于启动时在第一个检查周期之前得到 readiness
+func (c *Checker) Check() {
+	c.mu.RLock()
+	probes := make([]*probe, 0, len(c.probes))
+	for _, p := range c.probes {
+		probes = append(probes, p)
+	}
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p *probe) {
+			defer wg.Done()
+			c.check(p)
+		}(p)
+	}
+	wg.Wait()
+	c.notify()
+}
+
+func (c *Checker) check(p *probe) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	beg := time.Now()
+	err := call(ctx, p.fn)
+	cost := time.Since(beg)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev := p.result.Status
+	p.result.Latency = cost.String()
+	p.result.CheckedAt = time.Now()
+	if err == nil {
+		p.result.Status, p.result.Error, p.result.Failures = StatusUp, "", 0
+		xmonitor.HealthProbeGauge.WithLabelValues(p.name).Set(1)
+	} else {
+		p.result.Error = err.Error()
+		p.result.Failures++
+		if p.result.Failures >= p.threshold {
+			p.result.Status = StatusDown
+			xmonitor.HealthProbeGauge.WithLabelValues(p.name).Set(0)
+		}
+	}
+	if prev != p.result.Status && p.result.Status == StatusDown {
+		xlog.Warn("Health Probe Down",
+			xlog.FieldComponentName("XHealth"),
+			xlog.FieldName(p.name),
+			xlog.FieldCost(cost),
+			xlog.FieldErr(err),
+		)
+	} else if prev == StatusDown && p.result.Status == StatusUp {
+		xlog.Info("Health Probe Up",
+			xlog.FieldComponentName("XHealth"),
+			xlog.FieldName(p.name),
+			xlog.FieldCost(cost),
+		)
+	}
+}
+
+// call 探针未按时返回时以超时处理，探针 panic 视为失败
+func call(ctx context.Context, fn Probe) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("probe panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("probe timeout")
+	}
+}
+
+// Live 只统计 Liveness 探针，没有时总是 UP
+func (c *Checker) Live() Report {
+	return c.report(func(r Result) bool { return r.Liveness })
+}
+
+// Ready 统计所有 critical 探针，UNKNOWN 视为未就绪
+func (c *Checker) Ready() Report {
+	return c.report(func(r Result) bool { return r.Critical })
+}
+
+func (c *Checker) report(counted func(r Result) bool) Report {
+	c.mu.RLock()
+	results := make([]Result, 0, len(c.probes))
+	for _, p := range c.probes {
+		p.mu.Lock()
+		results = append(results, p.result)
+		p.mu.Unlock()
+	}
+	c.mu.RUnlock()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{Status: StatusUp, Checks: results}
+	for _, r := range results {
+		if counted(r) && r.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Watch 订阅 readiness 变化，注册时以当前状态调用一次，返回的函数用于取消订阅
+func (c *Checker) Watch(fn func(ready bool)) (cancel func()) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	id := c.watchSeq
+	c.watchSeq++
+	c.watchers[id] = fn
+	fn(c.Ready().Up())
+	return func() {
+		c.watchMu.Lock()
+		defer c.watchMu.Unlock()
+		delete(c.watchers, id)
+	}
+}
+
+// notify readiness 变化时按顺序通知订阅者
+func (c *Checker) notify() {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	ready := c.Ready().Up()
+	if c.ready != nil && *c.ready == ready {
+		return
+	}
+	c.ready = &ready
+	for _, fn := range c.watchers {
+		fn(ready)
+	}
+}
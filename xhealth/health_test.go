@@ -0,0 +1,153 @@
+package xhealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestChecker(t *testing.T) {
+	c := New()
+	if !c.Ready().Up() || !c.Live().Up() {
+		t.Fatal("checker without probes should be up")
+	}
+
+	var failing int32
+	c.Register("db", func(ctx context.Context) error {
+		if atomic.LoadInt32(&failing) == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, WithInterval(10*time.Millisecond))
+	c.Register("sms", func(ctx context.Context) error {
+		return errors.New("unreachable")
+	}, NonCritical(), WithInterval(10*time.Millisecond))
+	defer c.Deregister("db")
+	defer c.Deregister("sms")
+
+	waitFor(t, func() bool { return c.Ready().Up() })
+	report := c.Ready()
+	if len(report.Checks) != 2 || report.Checks[0].Name != "db" || report.Checks[1].Status != StatusDown {
+		t.Fatalf("report = %+v", report)
+	}
+
+	atomic.StoreInt32(&failing, 1)
+	waitFor(t, func() bool { return !c.Ready().Up() })
+	if !c.Live().Up() {
+		t.Fatal("readiness probe should not affect liveness")
+	}
+	if err := c.Ready().Checks[0].Error; err != "connection refused" {
+		t.Fatalf("error = %q", err)
+	}
+
+	c.Deregister("db")
+	if !c.Ready().Up() {
+		t.Fatal("deregistered probe should not affect readiness")
+	}
+}
+
+func TestProbeTimeoutAndThreshold(t *testing.T) {
+	c := New()
+	var calls int32
+	c.Register("slow", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Second)
+		return nil
+	}, WithTimeout(10*time.Millisecond), WithInterval(10*time.Millisecond), WithFailureThreshold(3))
+	defer c.Deregister("slow")
+
+	waitFor(t, func() bool { return c.Ready().Checks[0].Failures > 0 })
+	if r := c.Ready().Checks[0]; r.Status != StatusUnknown || r.Error != "probe timeout" {
+		t.Fatalf("below threshold: %+v", r)
+	}
+	waitFor(t, func() bool { return c.Ready().Checks[0].Status == StatusDown })
+	if atomic.LoadInt32(&calls) < 3 {
+		t.Fatalf("calls = %d", calls)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	c := New()
+	states := make(chan bool, 10)
+	cancel := c.Watch(func(ready bool) { states <- ready })
+	defer cancel()
+	if !<-states {
+		t.Fatal("initial state should be ready")
+	}
+
+	c.Register("db", func(ctx context.Context) error {
+		return errors.New("down")
+	}, WithInterval(time.Hour))
+	if <-states {
+		t.Fatal("unchecked probe should not be ready")
+	}
+	c.Deregister("db")
+	if !<-states {
+		t.Fatal("should be ready after deregister")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	Register("xhealth.test", func(ctx context.Context) error {
+		return errors.New("down")
+	}, WithInterval(time.Hour))
+	defer Deregister("xhealth.test")
+	waitFor(t, func() bool { return Ready().Checks[0].Status == StatusDown })
+
+	w := httptest.NewRecorder()
+	ReadinessHttp(w, httptest.NewRequest(http.MethodGet, "/debug/health/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness code = %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	LivenessHttp(w, httptest.NewRequest(http.MethodGet, "/debug/health/live", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("liveness code = %d", w.Code)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	c := New()
+	var calls int32
+	c.Register("db", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return ctx.Err()
+	}, WithInterval(0), WithTimeout(-time.Second))
+	defer c.Deregister("db")
+	waitFor(t, func() bool { return c.Ready().Up() })
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("calls = %d", calls)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := New()
+	c.Register("db", func(ctx context.Context) error {
+		return nil
+	}, WithInterval(time.Hour))
+	defer c.Deregister("db")
+	c.Register("cache", func(ctx context.Context) error {
+		return nil
+	}, WithInterval(time.Hour))
+	defer c.Deregister("cache")
+
+	c.Check()
+	if !c.Ready().Up() {
+		t.Fatalf("ready after check: %+v", c.Ready())
+	}
+}
@@ -0,0 +1,26 @@
+package xhealth
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// LivenessHttp liveness 检查，DOWN 时返回 503
+func LivenessHttp(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, Live())
+}
+
+// ReadinessHttp readiness 检查，DOWN 时返回 503，包含每个依赖的检查结果
+func ReadinessHttp(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, Ready())
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if report.Up() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
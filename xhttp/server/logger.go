@@ -0,0 +1,58 @@
+package servermiddleware
+
+import (
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhttp"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+type LoggerConfig struct {
+	SlowThreshold time.Duration `mapStructure:"slow_threshold"` // 超过该耗时的请求记录为慢请求
+	SampleRate    float64       `mapStructure:"sample_rate"`    // 正常请求的采样率 0~1，错误和慢请求总是记录
+}
+
+func DefaultLoggerConfig() *LoggerConfig {
+	return &LoggerConfig{
+		SlowThreshold: time.Second,
+		SampleRate:    1,
+	}
+}
+
+// AccessLog 访问日志，5xx 记录为 Error，4xx 和慢请求记录为 Warn
+func AccessLog(config *LoggerConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			beg := time.Now()
+			rw := wrap(w)
+			next.ServeHTTP(rw, r)
+			cost := time.Since(beg)
+
+			status := rw.Status()
+			slow := config.SlowThreshold > 0 && cost > config.SlowThreshold
+			if status < http.StatusBadRequest && !slow && config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+				return
+			}
+			fields := []xlog.Field{
+				xlog.FieldType("server"),
+				xlog.FieldType("http"),
+				xlog.FieldMethod(r.Method + " " + r.URL.Path),
+				xlog.FieldCode(int32(status)),
+				xlog.FieldCost(cost),
+				xlog.FieldPeerName(xhttp.Peer(r)),
+				xlog.FieldAddr(r.RemoteAddr),
+			}
+			switch {
+			case status >= http.StatusInternalServerError:
+				xlog.Error("HTTP Server Internal Error", fields...)
+			case status >= http.StatusBadRequest:
+				xlog.Warn("HTTP Client Error", fields...)
+			case slow:
+				xlog.Warn("HTTP Slow Request", fields...)
+			default:
+				xlog.Info("HTTP Access", fields...)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package servermiddleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coder2z/g-saber/xcast"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"github.com/coder2z/g-server/xhttp"
+	"github.com/coder2z/g-server/xmonitor"
+	"github.com/coder2z/g-server/xtrace"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc/codes"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// Middleware HTTP 中间件，与 gRPC 拦截器一一对应
+type Middleware func(next http.Handler) http.Handler
+
+var (
+	// ErrInternal 处理请求时发生 panic
+	ErrInternal = xcode.SystemCodeAdd(uint32(codes.Internal), "server internal error")
+	// ErrDeadlineExceeded 请求超时
+	ErrDeadlineExceeded = xcode.SystemCodeAdd(uint32(codes.DeadlineExceeded), "deadline budget exhausted")
+)
+
+// Chain 按顺序组合中间件，第一个中间件在最外层
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// responseWriter 记录状态码和是否已经写入响应
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func wrap(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack 供 websocket 等协议升级使用，接管连接后状态记为 101，之后不会再写入响应
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker is not supported")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) written() bool {
+	return w.status != 0
+}
+
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unmatched 没有匹配到路由的请求在指标和链路中使用的路由名
+const Unmatched = "unmatched"
+
+type routeKey struct{}
+
+// RouteFunc 返回请求匹配的路由模板，如 ServeMux 注册的 /users/，没有匹配时返回空
+type RouteFunc func(r *http.Request) string
+
+// Route 解析请求匹配的路由模板供指标和链路使用，应放在最外层；
+// 指标不使用原始路径，避免 /users/123 这类路径产生无限多的时间序列
+func Route(f RouteFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, f(r))))
+		})
+	}
+}
+
+var methods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// method 指标和链路中使用的方法名，由请求方法和路由模板组成，非标准的请求方法记为 OTHER
+func method(r *http.Request) string {
+	m := r.Method
+	if !methods[m] {
+		m = "OTHER"
+	}
+	route, _ := r.Context().Value(routeKey{}).(string)
+	if route == "" {
+		route = Unmatched
+	}
+	return m + " " + route
+}
+
+// Crash 恢复 panic，记录堆栈并返回 Internal 错误
+func Crash() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrap(w)
+			defer func() {
+				if rec := recover(); rec != nil {
+					var buf bytes.Buffer
+					buf.Write(debug.Stack())
+					xlog.Error(fmt.Sprintf("%+v", rec), xlog.FieldValue(buf.String()))
+					if !rw.written() {
+						xhttp.WriteError(rw, r, ErrInternal)
+					}
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// Prometheus 记录 ServerHandleCounter, ServerHandleHistogram, 5xx 计入 ServerErrorCounter；
+// X-App-Name 由客户端填写，不作为 peer 标签
+func Prometheus() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startTime := time.Now()
+			rw := wrap(w)
+			next.ServeHTTP(rw, r)
+			m, peer, code := method(r), "", xcast.ToString(rw.Status())
+			xmonitor.ServerHandleHistogram.WithLabelValues(xmonitor.TypeHTTP, xapp.Name(), m, peer).Observe(time.Since(startTime).Seconds())
+			xmonitor.ServerHandleCounter.WithLabelValues(xmonitor.TypeHTTP, xapp.Name(), m, peer, code).Inc()
+			if rw.Status() >= http.StatusInternalServerError {
+				xmonitor.ServerErrorCounter.WithLabelValues(xmonitor.TypeHTTP, xapp.Name(), m, peer, code).Inc()
+			}
+		})
+	}
+}
+
+// Trace 从请求头中提取上游链路并创建服务端 span
+func Trace() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span, ctx := xtrace.StartSpanFromContext(
+				r.Context(),
+				method(r),
+				xtrace.HeaderExtractor(r.Header),
+				xtrace.TagComponent("http"),
+				xtrace.TagSpanKind("server"),
+				xtrace.TagSpanURL(r.URL.String()),
+			)
+			defer span.Finish()
+			rw := wrap(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+			ext.HTTPStatusCode.Set(span, uint16(rw.Status()))
+			if rw.Status() >= http.StatusInternalServerError {
+				ext.Error.Set(span, true)
+			}
+		})
+	}
+}
+
+// Timeout 为请求设置超时，上游通过 x-deadline-budget 传递了剩余时间时以上游为准，没有传递时使用 timeout，
+// 处理函数因超时返回且没有写入响应时返回 DeadlineExceeded
+func Timeout(timeout, reserve time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			left := timeout
+			if v := r.Header.Get(deadline.BudgetKey); v != "" {
+				if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+					budget := time.Duration(ms)*time.Millisecond - reserve
+					if budget <= 0 {
+						xhttp.WriteError(w, r, ErrDeadlineExceeded)
+						return
+					}
+					left = budget
+				}
+			}
+			if left <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), left)
+			defer cancel()
+			rw := wrap(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+			if ctx.Err() == context.DeadlineExceeded && !rw.written() {
+				xhttp.WriteError(rw, r, ErrDeadlineExceeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package xhttp
+
+import (
+	"encoding/json"
+	"github.com/coder2z/g-saber/xjson"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xcode/i18n"
+	"github.com/golang/protobuf/jsonpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HeaderAppName 调用方应用名，对应 gRPC metadata 中的 app_name
+	HeaderAppName = "X-App-Name"
+	// HeaderAcceptLanguage 错误信息本地化使用的语言
+	HeaderAcceptLanguage = "Accept-Language"
+)
+
+// ErrorBody xcode 状态渲染的 JSON 结构，details 为 google.rpc 详情的 JSON 表示
+type ErrorBody struct {
+	Code    int32             `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// HTTPStatus gRPC 错误码对应的 HTTP 状态码，业务错误码统一为 400
+func HTTPStatus(code uint32) int {
+	if code > xcode.CodeBreakUp {
+		return http.StatusBadRequest
+	}
+	switch codes.Code(code) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode HTTP 状态码对应的 gRPC 错误码，客户端据此判断是否重试和熔断
+func GRPCCode(status int) codes.Code {
+	switch {
+	case status < http.StatusBadRequest:
+		return codes.OK
+	case status == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case status == http.StatusForbidden:
+		return codes.PermissionDenied
+	case status == http.StatusNotFound:
+		return codes.NotFound
+	case status == http.StatusConflict:
+		return codes.Aborted
+	case status == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case status == 499:
+		return codes.Canceled
+	case status < http.StatusInternalServerError:
+		return codes.InvalidArgument
+	case status == http.StatusNotImplemented:
+		return codes.Unimplemented
+	case status == http.StatusBadGateway, status == http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case status == http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// WriteJSON 以 JSON 输出响应
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = xjson.NewEncoder(w).Encode(v)
+}
+
+// WriteError 将错误转换为 xcode 状态并以 JSON 输出，按 Accept-Language 附加 LocalizedMessage
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	st := xcode.ExtractCodes(err)
+	if _, ok := st.LocalizedMessage(""); !ok && r != nil {
+		if locale, message, ok := st.Localize(i18n.ParseAcceptLanguage(r.Header.Get(HeaderAcceptLanguage))...); ok {
+			if localized, e := st.WithDetails(&errdetails.LocalizedMessage{Locale: locale, Message: message}); e == nil {
+				st = localized
+			}
+		}
+	}
+
+	body := ErrorBody{Code: st.Code, Message: st.Message}
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	for _, detail := range st.Details {
+		s, e := marshaler.MarshalToString(detail)
+		if e != nil {
+			continue
+		}
+		body.Details = append(body.Details, json.RawMessage(s))
+	}
+	WriteJSON(w, HTTPStatus(st.GetCodeAsUint32()), body)
+}
+
+// HandlerFunc 返回错误的处理函数，错误由 WriteError 渲染
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteError(w, r, err)
+	}
+}
+
+// Peer 调用方应用名，由客户端填写，只用于日志，没有时返回 unknown
+func Peer(r *http.Request) string {
+	if name := strings.TrimSpace(r.Header.Get(HeaderAppName)); name != "" {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,59 @@
+package xhttp
+
+import (
+	"errors"
+	"github.com/coder2z/g-saber/xjson"
+	"github.com/coder2z/g-server/xcode"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	errNotFound := xcode.BusinessCodeAdd(60001, "not found", xcode.Translation{Locale: "zh-CN", Message: "未找到"})
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errNotFound.MustWithDetails(&errdetails.ErrorInfo{Reason: "NOT_FOUND"})
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	r.Header.Set(HeaderAcceptLanguage, "zh-CN")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var body ErrorBody
+	if err := xjson.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != 60001 || body.Message != "not found" || len(body.Details) != 2 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"reason":"NOT_FOUND"`) || !strings.Contains(w.Body.String(), `"message":"未找到"`) {
+		t.Fatalf("unexpected details: %s", w.Body.String())
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := map[uint32]int{
+		0:     http.StatusOK,
+		3:     http.StatusBadRequest,
+		4:     http.StatusGatewayTimeout,
+		13:    http.StatusInternalServerError,
+		16:    http.StatusUnauthorized,
+		10001: http.StatusBadRequest,
+	}
+	for code, want := range cases {
+		if got := HTTPStatus(code); got != want {
+			t.Fatalf("HTTPStatus(%d) = %d, want %d", code, got, want)
+		}
+	}
+	w := httptest.NewRecorder()
+	WriteError(w, nil, errors.New("boom"))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", w.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package xserver
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-server/xapp"
+	servermiddleware "github.com/coder2z/g-server/xhttp/server"
+	"time"
+)
+
+type Config struct {
+	Host    string `mapStructure:"host"`
+	Port    int    `mapStructure:"port"`
+	Network string `mapStructure:"network"`
+
+	ReadTimeout       time.Duration `mapStructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapStructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapStructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapStructure:"idle_timeout"`
+	MaxHeaderBytes    int           `mapStructure:"max_header_bytes"`
+
+	CertFile string `mapStructure:"cert_file"`
+	KeyFile  string `mapStructure:"key_file"`
+
+	Timeout         time.Duration `mapStructure:"timeout"`          // timeout 中间件使用的默认超时
+	DeadlineReserve time.Duration `mapStructure:"deadline_reserve"` // timeout 中间件从上游预算中扣除的网络耗时
+	Middlewares     []string      `mapStructure:"middlewares"`      // 按顺序启用的中间件
+
+	Logger *servermiddleware.LoggerConfig `mapStructure:"logger"` // logger 中间件配置
+
+	key string
+}
+
+type Option func(c *Config)
+
+func DefaultConfig() *Config {
+	return &Config{
+		Host:              xapp.HostIP(),
+		Port:              8080,
+		Network:           "tcp",
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       time.Minute,
+		MaxHeaderBytes:    1 << 20,
+		Timeout:           5 * time.Second,
+		DeadlineReserve:   10 * time.Millisecond,
+		Middlewares:       []string{"crash", "prometheus", "trace", "logger", "timeout"},
+		Logger:            servermiddleware.DefaultLoggerConfig(),
+		key:               "app.http",
+	}
+}
+
+// RawConfig 读取 key 下的配置
+func RawConfig(key string) *Config {
+	config := xcfg.UnmarshalWithExpect(key, DefaultConfig()).(*Config)
+	config.key = key
+	return config
+}
+
+// StdConfig 读取 app.http 下的配置
+func StdConfig() *Config {
+	return RawConfig("app.http")
+}
+
+func (config Config) Address() string {
+	return fmt.Sprintf("%s:%d", config.Host, config.Port)
+}
+
+func (config Config) tls() bool {
+	return config.CertFile != "" && config.KeyFile != ""
+}
+
+func WithNetwork(network string) Option {
+	return func(c *Config) {
+		c.Network = network
+	}
+}
+
+func WithHost(host string) Option {
+	return func(c *Config) {
+		c.Host = host
+	}
+}
+
+func WithPort(port int) Option {
+	return func(c *Config) {
+		c.Port = port
+	}
+}
+
+func WithMiddlewares(names ...string) Option {
+	return func(c *Config) {
+		c.Middlewares = names
+	}
+}
@@ -0,0 +1,46 @@
+package xserver
+
+import (
+	"fmt"
+	servermiddleware "github.com/coder2z/g-server/xhttp/server"
+	"sync"
+)
+
+type MiddlewareBuilder func(c *Config) servermiddleware.Middleware
+
+var builders sync.Map
+
+func init() {
+	RegisterMiddleware("crash", func(*Config) servermiddleware.Middleware {
+		return servermiddleware.Crash()
+	})
+	RegisterMiddleware("prometheus", func(*Config) servermiddleware.Middleware {
+		return servermiddleware.Prometheus()
+	})
+	RegisterMiddleware("trace", func(*Config) servermiddleware.Middleware {
+		return servermiddleware.Trace()
+	})
+	RegisterMiddleware("logger", func(c *Config) servermiddleware.Middleware {
+		return servermiddleware.AccessLog(c.Logger)
+	})
+	RegisterMiddleware("timeout", func(c *Config) servermiddleware.Middleware {
+		return servermiddleware.Timeout(c.Timeout, c.DeadlineReserve)
+	})
+}
+
+// RegisterMiddleware 注册可以在配置中按名称启用的中间件
+func RegisterMiddleware(name string, builder MiddlewareBuilder) {
+	builders.Store(name, builder)
+}
+
+func (config *Config) middlewares() ([]servermiddleware.Middleware, error) {
+	middlewares := make([]servermiddleware.Middleware, 0, len(config.Middlewares))
+	for _, name := range config.Middlewares {
+		builder, ok := builders.Load(name)
+		if !ok {
+			return nil, fmt.Errorf("middleware %s not registered", name)
+		}
+		middlewares = append(middlewares, builder.(MiddlewareBuilder)(config))
+	}
+	return middlewares, nil
+}
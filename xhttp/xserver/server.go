@@ -0,0 +1,101 @@
+package xserver
+
+import (
+	"context"
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	servermiddleware "github.com/coder2z/g-server/xhttp/server"
+	"net"
+	"net/http"
+)
+
+type Server struct {
+	*http.ServeMux
+	server   *http.Server
+	config   *Config
+	listener net.Listener
+}
+
+// Build 按配置创建 HTTP 服务并监听端口，路由注册到返回的 Server 上，配置的中间件作用于所有路由
+func (config *Config) Build() (*Server, error) {
+	middlewares, err := config.middlewares()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen(config.Network, config.Address())
+	if err != nil {
+		return nil, err
+	}
+	// 端口为0时使用系统分配的端口
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok {
+		config.Port = addr.Port
+	}
+
+	s := &Server{
+		ServeMux: http.NewServeMux(),
+		config:   config,
+		listener: listener,
+	}
+	middlewares = append([]servermiddleware.Middleware{servermiddleware.Route(s.route)}, middlewares...)
+	s.server = &http.Server{
+		Handler:           servermiddleware.Chain(s.ServeMux, middlewares...),
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+	return s, nil
+}
+
+// route 请求在 ServeMux 中匹配的路由
+func (s *Server) route(r *http.Request) string {
+	_, pattern := s.ServeMux.Handler(r)
+	return pattern
+}
+
+// Config 服务使用的配置
+func (s *Server) Config() *Config {
+	return s.config
+}
+
+// Address 服务地址，用于服务注册
+func (s *Server) Address() string {
+	return s.config.Address()
+}
+
+func (s *Server) Serve() error {
+	xlog.Info("Application Starting",
+		xlog.FieldComponentName("XHttp"),
+		xlog.FieldMethod("XHttp.XServer.Serve"),
+		xlog.FieldDescription(fmt.Sprintf("HTTP serve running :%v", s.Address())),
+	)
+	var err error
+	if s.config.tls() {
+		err = s.server.ServeTLS(s.listener, s.config.CertFile, s.config.KeyFile)
+	} else {
+		err = s.server.Serve(s.listener)
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) Stop() error {
+	return s.server.Close()
+}
+
+// GracefulStop 停止接收新连接并等待处理中的请求完成，ctx 结束时返回 ctx.Err()
+func (s *Server) GracefulStop(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	xlog.Info("Application Stopping",
+		xlog.FieldComponentName("XHttp"),
+		xlog.FieldMethod("XHttp.XServer.GracefulStop"),
+		xlog.FieldDescription("HTTP server shutdown"),
+	)
+	return nil
+}
@@ -0,0 +1,146 @@
+package xserver
+
+import (
+	"bufio"
+	"context"
+	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"github.com/coder2z/g-server/xhttp"
+	"github.com/coder2z/g-server/xmonitor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	c := DefaultConfig()
+	WithHost("127.0.0.1")(c)
+	WithPort(0)(c)
+	c.Timeout = 100 * time.Millisecond
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		xhttp.WriteJSON(w, http.StatusOK, struct {
+			Name string `json:"name"`
+		}{"ok"})
+	})
+	s.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	s.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	s.HandleFunc("/budget", func(w http.ResponseWriter, r *http.Request) {
+		dl, _ := r.Context().Deadline()
+		xhttp.WriteJSON(w, http.StatusOK, struct {
+			Long bool `json:"long"`
+		}{time.Until(dl) > time.Second})
+	})
+	go func() {
+		_ = s.Serve()
+	}()
+
+	cases := []struct {
+		path   string
+		header map[string]string
+		status int
+		body   string
+	}{
+		{"/ok", nil, http.StatusOK, `"name":"ok"`},
+		{"/panic", nil, http.StatusInternalServerError, `"message":"server internal error"`},
+		{"/slow", nil, http.StatusGatewayTimeout, `"code":4`},
+		{"/ok", map[string]string{deadline.BudgetKey: "5"}, http.StatusGatewayTimeout, `"code":4`},
+		{"/budget", map[string]string{deadline.BudgetKey: "3000"}, http.StatusOK, `"long":true`},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+s.Address()+tc.path, nil)
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || !strings.Contains(string(body), tc.body) {
+			t.Fatalf("%s: status = %d, body = %s", tc.path, resp.StatusCode, body)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.GracefulStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerRouteAndHijack(t *testing.T) {
+	c := DefaultConfig()
+	WithHost("127.0.0.1")(c)
+	WithPort(0)(c)
+	s, err := c.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {})
+	s.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\nhello")
+		_ = rw.Flush()
+	})
+	go func() {
+		_ = s.Serve()
+	}()
+	defer s.Stop()
+
+	// 指标按路由模板聚合，未匹配的路径归入 unmatched
+	for _, path := range []string{"/users/1", "/users/2", "/scan/1", "/scan/2"} {
+		resp, err := http.Get("http://" + s.Address() + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if n := testutil.ToFloat64(xmonitor.ServerHandleCounter.WithLabelValues(xmonitor.TypeHTTP, xapp.Name(), "GET /users/", "", "200")); n != 2 {
+		t.Fatalf("users counter = %v", n)
+	}
+	if n := testutil.ToFloat64(xmonitor.ServerHandleCounter.WithLabelValues(xmonitor.TypeHTTP, xapp.Name(), "GET unmatched", "", "404")); n != 2 {
+		t.Fatalf("unmatched counter = %v", n)
+	}
+
+	conn, err := net.Dial("tcp", s.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n"))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestServerUnknownMiddleware(t *testing.T) {
+	c := DefaultConfig()
+	WithHost("127.0.0.1")(c)
+	WithPort(0)(c)
+	WithMiddlewares("unknown")(c)
+	if _, err := c.Build(); err == nil {
+		t.Fatal("expect unknown middleware error")
+	}
+}
@@ -1,8 +1,11 @@
 package xemail
 
 import (
+	"context"
 	"github.com/coder2z/g-saber/xcfg"
 	"gopkg.in/gomail.v2"
+	"net"
+	"strconv"
 )
 
 type Email struct {
@@ -29,6 +32,16 @@ func (e *Email) SendEmail(mailTo []string, subject string, body string) (err err
 	return err
 }
 
+// Ping 只建立到 SMTP 服务器的 TCP 连接，不发送邮件，供健康检查使用
+func (e *Email) Ping(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(e.o.Host, strconv.Itoa(e.o.Port)))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 func (i *emailInvoker) loadConfig() map[string]*options {
 	conf := make(map[string]*options)
 
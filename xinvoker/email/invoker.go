@@ -1,8 +1,10 @@
 package xemail
 
 import (
+	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xinvoker"
 	"sync"
 )
@@ -35,18 +37,29 @@ type emailInvoker struct {
 func (i *emailInvoker) Init(opts ...xinvoker.Option) error {
 	i.instances = sync.Map{}
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.newEmail(cfg))
+		i.store(name, i.newEmail(cfg))
 	}
 	return nil
 }
 
 func (i *emailInvoker) Reload(opts ...xinvoker.Option) error {
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.newEmail(cfg))
+		i.store(name, i.newEmail(cfg))
 	}
 	return nil
 }
 
+func (i *emailInvoker) store(name string, c *Email) {
+	i.instances.Store(name, c)
+	xhealth.RegisterInvoker(i.key, name, func(ctx context.Context) error {
+		return c.Ping(ctx)
+	}, xhealth.NonCritical())
+}
+
 func (i *emailInvoker) Close(opts ...xinvoker.Option) error {
+	i.instances.Range(func(key, value interface{}) bool {
+		xhealth.DeregisterInvoker(i.key, key.(string))
+		return true
+	})
 	return nil
 }
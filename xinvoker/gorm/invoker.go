@@ -1,8 +1,10 @@
 package xgorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xinvoker"
 	"gorm.io/gorm"
 	"sync"
@@ -38,7 +40,7 @@ func (i *dbInvoker) Init(opts ...xinvoker.Option) error {
 	i.instances = sync.Map{}
 	for name, cfg := range i.loadConfig() {
 		db := i.newDatabaseClient(cfg)
-		i.instances.Store(name, db)
+		i.store(name, db)
 	}
 	return nil
 }
@@ -46,13 +48,25 @@ func (i *dbInvoker) Init(opts ...xinvoker.Option) error {
 func (i *dbInvoker) Reload(opts ...xinvoker.Option) error {
 	for name, cfg := range i.loadConfig() {
 		db := i.newDatabaseClient(cfg)
-		i.instances.Store(name, db)
+		i.store(name, db)
 	}
 	return nil
 }
 
+func (i *dbInvoker) store(name string, db *gorm.DB) {
+	i.instances.Store(name, db)
+	xhealth.RegisterInvoker(i.key, name, func(ctx context.Context) error {
+		d, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return d.PingContext(ctx)
+	})
+}
+
 func (i *dbInvoker) Close(opts ...xinvoker.Option) error {
 	i.instances.Range(func(key, value interface{}) bool {
+		xhealth.DeregisterInvoker(i.key, key.(string))
 		db, _ := value.(*gorm.DB).DB()
 		_ = db.Close()
 		i.instances.Delete(key)
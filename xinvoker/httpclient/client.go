@@ -0,0 +1,293 @@
+package xhttpclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/coder2z/g-saber/xcast"
+	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xcode"
+	"github.com/coder2z/g-server/xgrpc/breaker"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"github.com/coder2z/g-server/xhttp"
+	"github.com/coder2z/g-server/xmonitor"
+	"github.com/coder2z/g-server/xtrace"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrBreakerOpen 熔断器拒绝请求
+var ErrBreakerOpen = xcode.SystemCodeAdd(uint32(codes.Unavailable), "circuit breaker is open")
+
+// Client 带链路、监控、超时、重试、熔断和服务发现的 HTTP 客户端
+type Client struct {
+	name      string
+	options   *options
+	client    *http.Client
+	base      *url.URL   // http(s) target
+	discovery *discovery // 服务发现 target
+	policy    *retry.Policy
+	breakers  *breaker.Group
+}
+
+func newClient(name string, o *options) (*Client, error) {
+	policy, err := retry.New(o.Retry)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		name:    name,
+		options: o,
+		policy:  policy,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				DialContext:         (&net.Dialer{Timeout: o.DialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+				MaxIdleConns:        o.MaxIdleConns,
+				MaxIdleConnsPerHost: o.MaxIdleConnsPerHost,
+				IdleConnTimeout:     o.IdleConnTimeout,
+			},
+		},
+	}
+
+	if strings.HasPrefix(o.Target, "http://") || strings.HasPrefix(o.Target, "https://") {
+		if c.base, err = url.Parse(o.Target); err != nil {
+			return nil, err
+		}
+	} else {
+		target, ok := parseTarget(o.Target)
+		if !ok {
+			return nil, fmt.Errorf("invalid target %q", o.Target)
+		}
+		if c.discovery, err = newDiscovery(target, o.Balancer); err != nil {
+			return nil, err
+		}
+	}
+	// 创建成功后才注册熔断器组，避免失败的配置残留在 /debug/breaker 中
+	if o.Breaker != nil {
+		c.breakers = breaker.NewGroup(o.Breaker)
+	}
+	return c, nil
+}
+
+type routeKey struct{}
+
+// WithRoute 为请求指定路由模板，如 /orders/{id}，用于监控标签、链路名和熔断；
+// 未指定时只使用请求方法，原始路径会让每个 id 各自产生一组监控和熔断器
+func WithRoute(req *http.Request, route string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), routeKey{}, route))
+}
+
+func routeOf(req *http.Request) string {
+	if route, _ := req.Context().Value(routeKey{}).(string); route != "" {
+		return req.Method + " " + route
+	}
+	return req.Method
+}
+
+// NewRequest 创建发往 target 的请求，path 为相对路径，实际地址在 Do 时确定
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.base != nil {
+		u = c.base.ResolveReference(u)
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// Do 发送请求，非 2xx/3xx 的响应不会返回错误；
+// 返回的 resp.Body 必须关闭，超时在关闭 Body 时释放
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if c.options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+	}
+	route := routeOf(req)
+	span, ctx := xtrace.StartSpanFromContext(
+		ctx,
+		"HTTP "+route,
+		xtrace.TagComponent("http"),
+		xtrace.TagSpanKind("client"),
+		xtrace.TagSpanURL(req.URL.String()),
+	)
+	defer span.Finish()
+
+	resp, attempts, err := c.do(ctx, req, route)
+	span.SetTag("http.attempts", attempts)
+	if err != nil {
+		cancel()
+		ext.Error.Set(span, true)
+		span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+		return nil, err
+	}
+	ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// retryable 幂等方法按原始路径匹配，只用于判断，不作为标签
+func (c *Client) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return c.policy.Idempotent(req.Method + " " + req.URL.Path)
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, route string) (*http.Response, int, error) {
+	retryable := c.retryable(req)
+	budget := c.policy.Budget(c.options.Target)
+	budget.Deposit()
+
+	var (
+		resp    *http.Response
+		err     error
+		attempt int
+		tried   = make(map[string]bool)
+	)
+	for {
+		attempt++
+		resp, err = c.attempt(ctx, req, route, tried)
+		if !retryable || attempt >= c.policy.MaxAttempts() {
+			break
+		}
+		code := status.Code(err)
+		if err == nil {
+			code = xhttp.GRPCCode(resp.StatusCode)
+		}
+		if code == codes.OK || !c.policy.Retryable(code) {
+			break
+		}
+		delay := c.policy.Backoff(attempt)
+		if dl, ok := ctx.Deadline(); ok && time.Until(dl) <= delay {
+			break
+		}
+		if !budget.Withdraw() {
+			xmonitor.ClientRetryCounter.WithLabelValues(xmonitor.TypeHTTP, c.name, route, c.options.Target, "budget exhausted").Inc()
+			break
+		}
+		xmonitor.ClientRetryCounter.WithLabelValues(xmonitor.TypeHTTP, c.name, route, c.options.Target, xcast.ToString(uint32(code))).Inc()
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if !sleep(ctx, delay) {
+			return nil, attempt, ctx.Err()
+		}
+	}
+	return resp, attempt, err
+}
+
+// attempt 选择实例并发送一次请求，记录监控并按实例和路由熔断
+func (c *Client) attempt(ctx context.Context, req *http.Request, route string, tried map[string]bool) (*http.Response, error) {
+	r := req.Clone(ctx)
+	if req.GetBody != nil && len(tried) > 0 {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	if c.discovery != nil {
+		host, err := c.discovery.pick(ctx, tried)
+		if err != nil {
+			return nil, err
+		}
+		r.URL.Scheme, r.URL.Host, r.Host = c.options.Scheme, host, host
+	}
+	peer := r.URL.Host
+	tried[peer] = true
+
+	r.Header.Set(xhttp.HeaderAppName, xapp.Name())
+	if dl, ok := ctx.Deadline(); ok {
+		r.Header.Set(deadline.BudgetKey, strconv.FormatInt(int64(time.Until(dl)/time.Millisecond), 10))
+	}
+	xtrace.HeaderInjector(ctx, r.Header)
+
+	var resp *http.Response
+	call := func() error {
+		var err error
+		resp, err = c.client.Do(r)
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if code := xhttp.GRPCCode(resp.StatusCode); code != codes.OK {
+			return status.Error(code, resp.Status)
+		}
+		return nil
+	}
+
+	beg := time.Now()
+	var err error
+	if c.breakers != nil {
+		err = c.breakers.Do(peer, route, call)
+		if err == breaker.ErrNotAllowed {
+			return nil, ErrBreakerOpen
+		}
+	} else {
+		err = call()
+	}
+
+	code := "0"
+	if resp != nil {
+		code = xcast.ToString(resp.StatusCode)
+	} else if err != nil {
+		code = status.Code(err).String()
+	}
+	xmonitor.ClientHandleHistogram.WithLabelValues(xmonitor.TypeHTTP, c.name, route, peer).Observe(time.Since(beg).Seconds())
+	xmonitor.ClientHandleCounter.WithLabelValues(xmonitor.TypeHTTP, c.name, route, peer, code).Inc()
+
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
+
+// Close 关闭服务发现、空闲连接和熔断器组
+func (c *Client) Close() error {
+	if c.discovery != nil {
+		c.discovery.Close()
+	}
+	if c.breakers != nil {
+		c.breakers.Close()
+	}
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
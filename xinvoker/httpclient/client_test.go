@@ -0,0 +1,211 @@
+package xhttpclient
+
+import (
+	"context"
+	"errors"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-server/xgrpc/breaker"
+	"github.com/coder2z/g-server/xgrpc/deadline"
+	"github.com/coder2z/g-server/xhttp"
+	"github.com/coder2z/g-server/xmonitor"
+	"github.com/coder2z/g-server/xregistry/xdirect"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/resolver"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get(deadline.BudgetKey) == "" {
+			t.Errorf("missing %s header", deadline.BudgetKey)
+		}
+		if _, ok := r.Header[xhttp.HeaderAppName]; !ok {
+			t.Errorf("missing %s header", xhttp.HeaderAppName)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	o := newHTTPOptions()
+	o.Target = srv.URL
+	o.Retry.BaseDelay = time.Millisecond
+	c, err := newClient("test", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "/ping", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("got %d %q", resp.StatusCode, body)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestClientNoRetryPost(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	o := newHTTPOptions()
+	o.Target = srv.URL
+	c, err := newClient("test", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	req, _ := c.NewRequest(context.Background(), http.MethodPost, "/order", strings.NewReader("{}"))
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Fatalf("got %d after %d calls", resp.StatusCode, calls)
+	}
+}
+
+func TestClientDiscovery(t *testing.T) {
+	_ = xdirect.RegisterBuilder()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	o := newHTTPOptions()
+	o.Target = "direct://ns///" + strings.TrimPrefix(srv.URL, "http://") + "?w=2"
+	c, err := newClient("test", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "/users/1", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != "/users/1" {
+		t.Fatalf("body = %q", body)
+	}
+}
+
+func TestClientRoute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	o := newHTTPOptions()
+	o.Target = srv.URL
+	o.Breaker = breaker.DefaultGroupConfig()
+	c, err := newClient("test", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// 同一路由模板的请求共享监控标签和熔断器，未指定模板时只按请求方法区分
+	for _, path := range []string{"/orders/1", "/orders/2", "/users/1", "/users/2"} {
+		req, _ := c.NewRequest(context.Background(), http.MethodGet, path, nil)
+		if strings.HasPrefix(path, "/orders/") {
+			req = WithRoute(req, "/orders/{id}")
+		}
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	peer := strings.TrimPrefix(srv.URL, "http://")
+	for _, route := range []string{"GET /orders/{id}", "GET"} {
+		if n := testutil.ToFloat64(xmonitor.ClientHandleCounter.WithLabelValues(xmonitor.TypeHTTP, "test", route, peer, "200")); n != 2 {
+			t.Fatalf("%s: counter = %v", route, n)
+		}
+	}
+}
+
+type failedBuilder struct{}
+
+func (failedBuilder) Build(resolver.Target, resolver.ClientConn, resolver.BuildOptions) (resolver.Resolver, error) {
+	return nil, errors.New("registry unreachable")
+}
+
+func (failedBuilder) Scheme() string { return "failed" }
+
+func TestClientDiscoveryBuildError(t *testing.T) {
+	resolver.Register(failedBuilder{})
+	o := newHTTPOptions()
+	o.Target = "failed://ns/user"
+	c, err := newClient("test", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = c.discovery.pick(ctx, nil)
+	if err == nil || !strings.Contains(err.Error(), "registry unreachable") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestInvokerReload(t *testing.T) {
+	defer func(d time.Duration) { drainTimeout = d }(drainTimeout)
+	drainTimeout = 10 * time.Millisecond
+
+	_ = xcfg.Apply(map[string]interface{}{
+		"reload": map[string]interface{}{"client": map[string]interface{}{
+			"user": map[string]interface{}{"target": "http://127.0.0.1:1"},
+		}},
+	})
+	invoker := Register("reload.client")
+	if err := invoker.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer invoker.Close()
+	old := Invoker("user")
+
+	// 配置未变化时不重建，新配置错误时保留旧客户端
+	_ = invoker.Reload()
+	_ = xcfg.Apply(map[string]interface{}{
+		"reload": map[string]interface{}{"client": map[string]interface{}{
+			"user": map[string]interface{}{"target": "invalid"},
+		}},
+	})
+	_ = invoker.Reload()
+	if Invoker("user") != old {
+		t.Fatal("client should be kept")
+	}
+
+	// 配置中已删除的客户端被移除
+	httpI.instances.Store("removed", old)
+	_ = invoker.Reload()
+	if _, ok := httpI.instances.Load("removed"); ok {
+		t.Fatal("removed client should be deleted")
+	}
+}
@@ -0,0 +1,46 @@
+package xhttpclient
+
+import (
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xlog"
+)
+
+func (i *httpInvoker) newHTTPClient(name string, o *options) *Client {
+	c, err := newClient(name, o)
+	if err != nil {
+		xlog.Panic("Application Starting",
+			xlog.FieldComponentName("XInvoker"),
+			xlog.FieldMethod("XInvoker.XHttpClient.NewHTTPClient"),
+			xlog.FieldDescription("New HTTPClient error"),
+			xlog.FieldName(name),
+			xlog.FieldErr(err),
+		)
+	}
+	return c
+}
+
+// reloadHTTPClient 热更新时使用，失败时保留旧客户端
+func (i *httpInvoker) reloadHTTPClient(name string, o *options) (*Client, bool) {
+	c, err := newClient(name, o)
+	if err != nil {
+		xlog.Error("Application Reload",
+			xlog.FieldComponentName("XInvoker"),
+			xlog.FieldMethod("XInvoker.XHttpClient.Reload"),
+			xlog.FieldDescription("Reload HTTPClient error, keep previous client"),
+			xlog.FieldName(name),
+			xlog.FieldErr(err),
+		)
+		return nil, false
+	}
+	return c, true
+}
+
+func (i *httpInvoker) loadConfig() map[string]*options {
+	conf := make(map[string]*options)
+	prefix := i.key
+	for name := range xcfg.GetStringMap(prefix) {
+		cfg := xcfg.UnmarshalWithExpect(prefix+"."+name, newHTTPOptions()).(*options)
+		conf[name] = cfg
+	}
+	return conf
+}
@@ -0,0 +1,89 @@
+package xhttpclient
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xinvoker"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// drainTimeout 热更新替换或删除客户端后，等待进行中的请求结束再关闭旧客户端
+var drainTimeout = 30 * time.Second
+
+var httpI *httpInvoker
+
+func Register(k string) xinvoker.Invoker {
+	httpI = &httpInvoker{key: k}
+	return httpI
+}
+
+func Invoker(key string) *Client {
+	if val, ok := httpI.instances.Load(key); ok {
+		return val.(*Client)
+	}
+	xlog.Panic("Application Starting",
+		xlog.FieldComponentName("XInvoker"),
+		xlog.FieldMethod("XInvoker.XHttpClient"),
+		xlog.FieldDescription(fmt.Sprintf("no http client(%s) invoker found", key)),
+	)
+	return nil
+}
+
+type httpInvoker struct {
+	xinvoker.Base
+	instances sync.Map
+	key       string
+}
+
+func (i *httpInvoker) Init(opts ...xinvoker.Option) error {
+	i.instances = sync.Map{}
+	for name, cfg := range i.loadConfig() {
+		i.instances.Store(name, i.newHTTPClient(name, cfg))
+	}
+	return nil
+}
+
+// Reload 仅重建配置发生变化的客户端，新配置创建失败时保留旧客户端；
+// 被替换或已从配置中删除的客户端在 drainTimeout 后关闭
+func (i *httpInvoker) Reload(opts ...xinvoker.Option) error {
+	configs := i.loadConfig()
+	for name, cfg := range configs {
+		old, loaded := i.instances.Load(name)
+		if loaded && reflect.DeepEqual(old.(*Client).options, cfg) {
+			continue
+		}
+		c, ok := i.reloadHTTPClient(name, cfg)
+		if !ok {
+			continue
+		}
+		i.instances.Store(name, c)
+		if loaded {
+			drain(old.(*Client))
+		}
+	}
+	i.instances.Range(func(key, value interface{}) bool {
+		if _, ok := configs[key.(string)]; !ok {
+			i.instances.Delete(key)
+			drain(value.(*Client))
+		}
+		return true
+	})
+	return nil
+}
+
+func drain(c *Client) {
+	time.AfterFunc(drainTimeout, func() {
+		_ = c.Close()
+	})
+}
+
+func (i *httpInvoker) Close(opts ...xinvoker.Option) error {
+	i.instances.Range(func(key, value interface{}) bool {
+		_ = value.(*Client).Close()
+		i.instances.Delete(key)
+		return true
+	})
+	return nil
+}
@@ -0,0 +1,42 @@
+package xhttpclient
+
+import (
+	"github.com/coder2z/g-server/xgrpc/breaker"
+	"github.com/coder2z/g-server/xgrpc/retry"
+	"time"
+)
+
+const (
+	RoundRobin = "round_robin"
+	Random     = "random"
+)
+
+type options struct {
+	// Target 服务地址，http(s)://host 直接访问，
+	// etcd://namespaces/name, k8s://namespace/name:port, direct://ns/host1:port?w=1,host2:port 通过服务发现访问
+	Target   string `mapStructure:"target"`
+	Scheme   string `mapStructure:"scheme"`   // 通过服务发现访问时使用的协议，http 或 https
+	Balancer string `mapStructure:"balancer"` // 服务发现的负载均衡: round_robin, random, 都会按 weight 加权
+
+	Timeout             time.Duration `mapStructure:"timeout"`      // 单次调用的总超时，包含重试
+	DialTimeout         time.Duration `mapStructure:"dial_timeout"` // 建立连接的超时
+	MaxIdleConns        int           `mapStructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `mapStructure:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `mapStructure:"idle_conn_timeout"`
+
+	Retry   *retry.Config        `mapStructure:"retry"`   // 重试策略，methods 为 "GET /path/*" 形式，GET/HEAD/OPTIONS 默认可重试
+	Breaker *breaker.GroupConfig `mapStructure:"breaker"` // 按实例和路由熔断，路由由 WithRoute 指定，为空时不熔断
+}
+
+func newHTTPOptions() *options {
+	return &options{
+		Scheme:              "http",
+		Balancer:            RoundRobin,
+		Timeout:             3 * time.Second,
+		DialTimeout:         time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		Retry:               retry.DefaultConfig(),
+	}
+}
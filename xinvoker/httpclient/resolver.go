@@ -0,0 +1,153 @@
+package xhttpclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	xbalancer "github.com/coder2z/g-server/xgrpc/balancer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+	"google.golang.org/grpc/status"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseTarget scheme://authority/endpoint，与 grpc.Dial 的 target 格式相同
+func parseTarget(target string) (resolver.Target, bool) {
+	i := strings.Index(target, "://")
+	if i < 0 {
+		return resolver.Target{}, false
+	}
+	t := resolver.Target{Scheme: target[:i]}
+	rest := target[i+3:]
+	j := strings.Index(rest, "/")
+	if j < 0 {
+		return resolver.Target{}, false
+	}
+	t.Authority, t.Endpoint = rest[:j], rest[j+1:]
+	return t, true
+}
+
+// discovery 复用 xregistry 注册的 gRPC resolver(etcd/k8s/direct)获取实例地址，并在客户端按权重负载均衡
+type discovery struct {
+	balancer string
+
+	mu       sync.Mutex
+	addrs    []string // 按权重展开后的地址
+	next     int
+	r        *rand.Rand
+	ready    chan struct{} // 收到第一批地址或 resolver 创建失败时关闭
+	once     sync.Once
+	err      error // resolver 创建失败的原因
+	resolver resolver.Resolver
+	closed   bool
+}
+
+func newDiscovery(target resolver.Target, balancer string) (*discovery, error) {
+	builder := resolver.Get(target.Scheme)
+	if builder == nil {
+		return nil, fmt.Errorf("resolver %s not registered", target.Scheme)
+	}
+	d := &discovery{
+		balancer: balancer,
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		ready:    make(chan struct{}),
+	}
+	// etcd, k8s 的 Build 会等待第一次解析结果，避免阻塞启动
+	go func() {
+		r, err := builder.Build(target, d, resolver.BuildOptions{})
+		if err != nil {
+			xlog.Error("Application Starting",
+				xlog.FieldComponentName("XInvoker"),
+				xlog.FieldMethod("XInvoker.XHttpClient.Discovery"),
+				xlog.FieldAddr(target.Scheme+"://"+target.Authority+"/"+target.Endpoint),
+				xlog.FieldErr(err),
+			)
+			d.mu.Lock()
+			d.err = err
+			d.mu.Unlock()
+			d.once.Do(func() { close(d.ready) })
+			return
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.closed {
+			r.Close()
+			return
+		}
+		d.resolver = r
+	}()
+	return d, nil
+}
+
+func (d *discovery) UpdateState(state resolver.State) {
+	d.NewAddress(state.Addresses)
+}
+
+func (d *discovery) ReportError(error) {}
+
+func (d *discovery) NewAddress(addresses []resolver.Address) {
+	addrs := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		for i := 0; i < xbalancer.GetWeight(addr); i++ {
+			addrs = append(addrs, addr.Addr)
+		}
+	}
+	// 打乱顺序，避免同一个实例的权重集中在一起
+	d.mu.Lock()
+	d.r.Shuffle(len(addrs), func(i, j int) { addrs[i], addrs[j] = addrs[j], addrs[i] })
+	d.addrs = addrs
+	d.mu.Unlock()
+	if len(addrs) > 0 {
+		d.once.Do(func() { close(d.ready) })
+	}
+}
+
+func (d *discovery) NewServiceConfig(string) {}
+
+func (d *discovery) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return &serviceconfig.ParseResult{}
+}
+
+// pick 选择一个实例，优先避开 exclude 中已经尝试过的实例
+func (d *discovery) pick(ctx context.Context, exclude map[string]bool) (string, error) {
+	select {
+	case <-d.ready:
+	case <-ctx.Done():
+		return "", status.Error(codes.Unavailable, "no available instance: "+ctx.Err().Error())
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return "", status.Error(codes.Unavailable, "resolver build failed: "+d.err.Error())
+	}
+	if len(d.addrs) == 0 {
+		return "", status.Error(codes.Unavailable, "no available instance")
+	}
+	var start int
+	if d.balancer == Random {
+		start = d.r.Intn(len(d.addrs))
+	} else {
+		start = d.next
+		d.next = (d.next + 1) % len(d.addrs)
+	}
+	for i := 0; i < len(d.addrs); i++ {
+		addr := d.addrs[(start+i)%len(d.addrs)]
+		if !exclude[addr] {
+			return addr, nil
+		}
+	}
+	return d.addrs[start%len(d.addrs)], nil
+}
+
+func (d *discovery) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.resolver != nil {
+		d.resolver.Close()
+	}
+}
@@ -1,8 +1,10 @@
 package xmongo
 
 import (
+	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xinvoker"
 	"sync"
 )
@@ -36,7 +38,7 @@ func (i *mongoInvoker) Init(opts ...xinvoker.Option) error {
 	i.instances = sync.Map{}
 	for name, cfg := range i.loadConfig() {
 		log := i.new(cfg)
-		i.instances.Store(name, log)
+		i.store(name, log)
 	}
 	return nil
 }
@@ -44,11 +46,27 @@ func (i *mongoInvoker) Init(opts ...xinvoker.Option) error {
 func (i *mongoInvoker) Reload(opts ...xinvoker.Option) error {
 	for name, cfg := range i.loadConfig() {
 		log := i.new(cfg)
-		i.instances.Store(name, log)
+		i.store(name, log)
 	}
 	return nil
 }
 
+func (i *mongoInvoker) store(name string, c MongoImp) {
+	i.instances.Store(name, c)
+	p, ok := c.(Pinger)
+	if !ok {
+		xhealth.DeregisterInvoker(i.key, name)
+		return
+	}
+	xhealth.RegisterInvoker(i.key, name, func(ctx context.Context) error {
+		return p.Ping(ctx)
+	})
+}
+
 func (i *mongoInvoker) Close(opts ...xinvoker.Option) error {
+	i.instances.Range(func(key, value interface{}) bool {
+		xhealth.DeregisterInvoker(i.key, key.(string))
+		return true
+	})
 	return nil
 }
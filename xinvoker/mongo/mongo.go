@@ -1,9 +1,11 @@
 package xmongo
 
 import (
+	"context"
 	"github.com/coder2z/g-saber/xcfg"
 	"github.com/coder2z/g-saber/xlog"
 	"github.com/globalsign/mgo"
+	"time"
 )
 
 type MongoImp interface {
@@ -15,6 +17,11 @@ type MongoImp interface {
 	Remove(db, collection string, query interface{}) error
 }
 
+// Pinger 可选接口，实现了 Ping 的 MongoImp 会注册健康检查探针
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 type client struct {
 	m *mgo.Session
 }
@@ -54,6 +61,31 @@ func (c *client) Remove(db, collection string, query interface{}) error {
 	return mc.Remove(query)
 }
 
+// Ping 使用新的会话检查连接，供健康检查使用，ctx 的截止时间作为会话的超时时间
+func (c *client) Ping(ctx context.Context) error {
+	s := c.m.Copy()
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		if timeout <= 0 {
+			s.Close()
+			return context.DeadlineExceeded
+		}
+		s.SetSyncTimeout(timeout)
+		s.SetSocketTimeout(timeout)
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer s.Close()
+		done <- s.Ping()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (i *mongoInvoker) loadConfig() map[string]*options {
 	conf := make(map[string]*options)
 
@@ -1,6 +1,7 @@
 package alioss
 
 import (
+	"fmt"
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 	"github.com/coder2z/g-server/xinvoker/oss/standard"
 	"io"
@@ -32,7 +33,11 @@ func NewOss(endpoints, accessKeyId, accessKeySecret, bucketName string, isDelete
 }
 
 func (c *Client) PutObject(dstPath string, reader io.Reader, options ...standard.Option) error {
-	return c.b.PutObject(dstPath, reader)
+	opts, err := ossOptions(options)
+	if err != nil {
+		return err
+	}
+	return c.b.PutObject(dstPath, reader, opts...)
 }
 
 func (c *Client) SignURL(dstPath string, method string, expiredInSec int64, options ...standard.Option) (string, error) {
@@ -40,7 +45,11 @@ func (c *Client) SignURL(dstPath string, method string, expiredInSec int64, opti
 }
 
 func (c *Client) PutObjectFromFile(dstPath, srcPath string, options ...standard.Option) (err error) {
-	err = c.b.PutObjectFromFile(dstPath, srcPath)
+	opts, err := ossOptions(options)
+	if err != nil {
+		return
+	}
+	err = c.b.PutObjectFromFile(dstPath, srcPath, opts...)
 	if err != nil {
 		return
 	}
@@ -53,12 +62,60 @@ func (c *Client) PutObjectFromFile(dstPath, srcPath string, options ...standard.
 
 func (c *Client) GetObject(dstPath string, options ...standard.Option) (ouput []byte, err error) {
 	var reader io.ReadCloser
-	reader, err = c.b.GetObject(dstPath)
+	reader, err = c.GetObjectReader(dstPath, options...)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
 	return ioutil.ReadAll(reader)
 }
 
 func (c *Client) GetObjectToFile(dstPath, srcPath string, options ...standard.Option) error {
-	return c.b.GetObjectToFile(dstPath, srcPath)
+	opts, err := ossOptions(options)
+	if err != nil {
+		return err
+	}
+	return c.b.GetObjectToFile(dstPath, srcPath, opts...)
+}
+
+func (c *Client) GetObjectReader(dstPath string, options ...standard.Option) (io.ReadCloser, error) {
+	opts, err := ossOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return c.b.GetObject(dstPath, opts...)
+}
+
+func (c *Client) UploadFile(dstPath, srcPath string, partSize int64, options ...standard.Option) (err error) {
+	opts, err := ossOptions(options)
+	if err != nil {
+		return
+	}
+	err = c.b.UploadFile(dstPath, srcPath, partSize, opts...)
+	if err != nil {
+		return
+	}
+	if c.isDelete {
+		err = os.Remove(srcPath)
+	}
+	return
+}
+
+func (c *Client) CopyObject(srcPath, dstPath string, options ...standard.Option) error {
+	opts, err := ossOptions(options)
+	if err != nil {
+		return err
+	}
+	_, err = c.b.CopyObject(srcPath, dstPath, opts...)
+	return err
+}
+
+// MoveObject OSS 不支持重命名，复制成功后删除源对象
+func (c *Client) MoveObject(srcPath, dstPath string, options ...standard.Option) error {
+	if err := c.CopyObject(srcPath, dstPath, options...); err != nil {
+		return err
+	}
+	return c.b.DeleteObject(srcPath)
 }
 
 func (c *Client) DeleteObject(dstPath string) (err error) {
@@ -94,9 +151,13 @@ func (c *Client) ListObjects(options ...standard.Option) (standard.ListObjectsRe
 		err       error
 		result    oss.ListObjectsResult
 	)
-	result, err = c.b.ListObjects()
+	opts, err := ossOptions(options)
+	if err != nil {
+		return standard.ListObjectsResult{}, err
+	}
+	result, err = c.b.ListObjects(opts...)
 	if err != nil {
-		return standard.ListObjectsResult{}, nil
+		return standard.ListObjectsResult{}, err
 	}
 	for _, i := range result.Objects {
 		tmpObject = standard.ObjectProperties{
@@ -123,3 +184,57 @@ func (c *Client) ListObjects(options ...standard.Option) (standard.ListObjectsRe
 		CommonPrefixes: result.CommonPrefixes,
 	}, err
 }
+
+// ossOptions 转换为 SDK 的选项
+func ossOptions(options []standard.Option) ([]oss.Option, error) {
+	o, err := standard.ParseOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	var opts []oss.Option
+	if o.ContentType != "" {
+		opts = append(opts, oss.ContentType(o.ContentType))
+	}
+	if o.Prefix != "" {
+		opts = append(opts, oss.Prefix(o.Prefix))
+	}
+	if o.Marker != "" {
+		opts = append(opts, oss.Marker(o.Marker))
+	}
+	if o.MaxKeys > 0 {
+		opts = append(opts, oss.MaxKeys(o.MaxKeys))
+	}
+	if o.Delimiter != "" {
+		opts = append(opts, oss.Delimiter(o.Delimiter))
+	}
+	if o.Range != nil {
+		if o.Range.End < 0 {
+			opts = append(opts, oss.NormalizedRange(fmt.Sprintf("%d-", o.Range.Start)))
+		} else {
+			opts = append(opts, oss.Range(o.Range.Start, o.Range.End))
+		}
+	}
+	if o.Progress != nil {
+		opts = append(opts, oss.Progress(progressListener(o.Progress)))
+	}
+	if o.Routines > 0 {
+		opts = append(opts, oss.Routines(o.Routines))
+	}
+	if o.Checkpoint {
+		opts = append(opts, oss.Checkpoint(true, o.CheckpointPath))
+	}
+	return opts, nil
+}
+
+type progressListener standard.ProgressFunc
+
+func (fn progressListener) ProgressChanged(event *oss.ProgressEvent) {
+	switch event.EventType {
+	case oss.TransferDataEvent, oss.TransferCompletedEvent:
+		total := event.TotalBytes
+		if total <= 0 {
+			total = -1
+		}
+		fn(event.ConsumedBytes, total)
+	}
+}
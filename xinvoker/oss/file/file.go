@@ -6,99 +6,233 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
 )
 
+// Client 使用本地目录模拟对象存储，bucket 为根目录，对象元数据保存在 bucket/.xoss-meta 下；
+// 配置 secret 后 SignURL 生成带签名和过期时间的地址，由 ServeHTTP 校验并提供下载和上传
 type Client struct {
 	isDelete bool
 	bucket   string
 	cdnName  string
+	secret   string
 }
 
-func NewOss(cdnName string, bucket string, isDelete bool) (client standard.Oss, err error) {
+func NewOss(cdnName, bucket, secret string, isDelete bool) (client standard.Oss, err error) {
 	client = &Client{
 		isDelete: isDelete,
 		bucket:   bucket,
 		cdnName:  cdnName,
+		secret:   secret,
 	}
 	return
 }
 
+// key 规范化对象名，去掉开头的 / 和其中的 ..，保证不会超出 bucket 目录
+func key(dstPath string) string {
+	return path.Clean("/" + dstPath)[1:]
+}
+
+// path 对象在本地的路径
+func (c *Client) path(dstPath string) string {
+	return filepath.Join(c.bucket, filepath.FromSlash(key(dstPath)))
+}
+
+// errReservedKey 对象名指向元数据目录或写入过程中的临时文件
+var errReservedKey = errors.New("reserved object key")
+
+// checkKey 与 ServeHTTP 一致，拒绝 hidden 的对象名，避免通过 Client 读写元数据和临时文件
+func checkKey(dstPaths ...string) error {
+	for _, dstPath := range dstPaths {
+		if hidden(key(dstPath)) {
+			return errReservedKey
+		}
+	}
+	return nil
+}
+
 func (c *Client) PutObject(dstPath string, reader io.Reader, options ...standard.Option) error {
-	dstPath = c.bucket + "/" + dstPath
-	err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm)
+	if err := checkKey(dstPath); err != nil {
+		return err
+	}
+	opts, err := standard.ParseOptions(options)
 	if err != nil {
 		return err
 	}
-
-	fileByte, err := ioutil.ReadAll(reader)
+	if opts.Progress != nil {
+		reader = standard.ProgressReader(reader, standard.ReaderLen(reader), opts.Progress)
+	}
+	etag, err := writeFile(c.path(dstPath), reader)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(dstPath, fileByte, os.ModePerm)
+	return c.writeMeta(dstPath, opts.ContentType, etag)
 }
 
 func (c *Client) PutObjectFromFile(dstPath, srcPath string, options ...standard.Option) (err error) {
-	dstPath = c.bucket + "/" + dstPath
-	err = os.MkdirAll(filepath.Dir(dstPath), os.ModePerm)
+	var f *os.File
+	f, err = os.Open(srcPath)
 	if err != nil {
 		return
 	}
-	var b []byte
-	b, err = ioutil.ReadFile(srcPath)
+	err = c.PutObject(dstPath, f, options...)
+	_ = f.Close()
 	if err != nil {
 		return
 	}
 
-	err = ioutil.WriteFile(dstPath, b, os.ModePerm)
+	if c.isDelete {
+		err = os.Remove(srcPath)
+	}
+	return
+}
+
+func (c *Client) GetObject(dstPath string, options ...standard.Option) (output []byte, err error) {
+	var reader io.ReadCloser
+	reader, err = c.GetObjectReader(dstPath, options...)
 	if err != nil {
 		return
 	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
 
+// GetObjectToFile 下载对象到本地文件 srcPath
+func (c *Client) GetObjectToFile(dstPath, srcPath string, options ...standard.Option) error {
+	reader, err := c.GetObjectReader(dstPath, options...)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = writeFile(srcPath, reader)
+	return err
+}
+
+// GetObjectReader Range 超出文件大小时读到末尾
+func (c *Client) GetObjectReader(dstPath string, options ...standard.Option) (io.ReadCloser, error) {
+	if err := checkKey(dstPath); err != nil {
+		return nil, err
+	}
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(c.path(dstPath))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Range == nil {
+		return f, nil
+	}
+	if _, err = f.Seek(opts.Range.Start, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if opts.Range.End < 0 {
+		return f, nil
+	}
+	return standard.ReadCloser(io.LimitReader(f, opts.Range.End-opts.Range.Start+1), f), nil
+}
+
+// UploadFile 本地文件按分片顺序复制，Routines 不生效；开启 Checkpoint 时中断后从最后完成的分片继续
+func (c *Client) UploadFile(dstPath, srcPath string, partSize int64, options ...standard.Option) (err error) {
+	if partSize <= 0 {
+		return errors.New("part size must be greater than 0")
+	}
+	if err = checkKey(dstPath); err != nil {
+		return
+	}
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return
+	}
+	err = newUpload(c.path(dstPath), srcPath, partSize, opts).run()
+	if err != nil {
+		return
+	}
+	etag, err := fileETag(c.path(dstPath))
+	if err != nil {
+		return
+	}
+	if err = c.writeMeta(dstPath, opts.ContentType, etag); err != nil {
+		return
+	}
 	if c.isDelete {
 		err = os.Remove(srcPath)
 	}
 	return
 }
 
-func (c *Client) GetObject(dstPath string, options ...standard.Option) (output []byte, err error) {
-	return ioutil.ReadFile(dstPath)
+// CopyObject 未指定 ContentType 时沿用源对象的
+func (c *Client) CopyObject(srcPath, dstPath string, options ...standard.Option) error {
+	if err := checkKey(srcPath, dstPath); err != nil {
+		return err
+	}
+	m, err := c.stat(srcPath)
+	if err != nil {
+		return err
+	}
+	r, err := c.GetObjectReader(srcPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return c.PutObject(dstPath, r, append([]standard.Option{standard.ContentType(m.ContentType)}, options...)...)
 }
 
-func (c *Client) GetObjectToFile(dstPath, srcPath string, options ...standard.Option) error {
-	return errors.New("file type is not supported GetObjectToFile method")
+func (c *Client) MoveObject(srcPath, dstPath string, options ...standard.Option) error {
+	if err := checkKey(srcPath, dstPath); err != nil {
+		return err
+	}
+	dst := c.path(dstPath)
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.Rename(c.path(srcPath), dst); err != nil {
+		return err
+	}
+	return c.moveMeta(srcPath, dstPath)
 }
 
+// DeleteObject 与 OSS 一致，对象不存在时不返回错误
 func (c *Client) DeleteObject(dstPath string) (err error) {
-	err = os.Remove(strings.TrimLeft(dstPath, "/"))
-	return
+	if err = checkKey(dstPath); err != nil {
+		return
+	}
+	err = os.Remove(c.path(dstPath))
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	return c.removeMeta(dstPath)
 }
 
 func (c *Client) DeleteObjects(dstPaths []string, options ...standard.Option) (output standard.DeleteObjectsResult, err error) {
-	for _, filePath := range dstPaths {
-		err1 := os.Remove(filePath)
+	for _, dstPath := range dstPaths {
+		err1 := c.DeleteObject(dstPath)
 		if err1 != nil {
 			if err != nil {
 				err = errors.New(err.Error() + ", err is " + err1.Error())
 			} else {
 				err = err1
 			}
+			continue
 		}
+		output.DeletedObjects = append(output.DeletedObjects, dstPath)
 	}
 	return
 }
 
 func (c *Client) IsObjectExist(dstPath string) (bool, error) {
-	_, err := os.Lstat(dstPath)
-	return !os.IsNotExist(err), nil
-}
-
-func (c *Client) ListObjects(options ...standard.Option) (standard.ListObjectsResult, error) {
-	return standard.ListObjectsResult{}, errors.New("file type is not supported ListObjects method")
-}
-
-func (c *Client) SignURL(dstPath string, method string, expiredInSec int64, options ...standard.Option) (resp string, err error) {
-	resp = c.cdnName + dstPath
-	return
+	if err := checkKey(dstPath); err != nil {
+		return false, err
+	}
+	info, err := os.Stat(c.path(dstPath))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
 }
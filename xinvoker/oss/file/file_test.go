@@ -0,0 +1,359 @@
+package file
+
+import (
+	"bytes"
+	"github.com/coder2z/g-server/xinvoker/oss/standard"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestOss(t *testing.T) (*Client, string) {
+	dir, err := ioutil.TempDir("", "xoss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	c, _ := NewOss("http://oss.local/files", filepath.Join(dir, "bucket"), "secret", false)
+	return c.(*Client), dir
+}
+
+func TestStream(t *testing.T) {
+	c, _ := newTestOss(t)
+
+	var consumed, total int64
+	err := c.PutObject("a/b.txt", strings.NewReader("0123456789"), standard.Progress(func(n, t int64) {
+		consumed, total = n, t
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consumed != 10 || total != 10 {
+		t.Fatalf("progress = %d/%d", consumed, total)
+	}
+	if info, err := os.Stat(c.path("a/b.txt")); err != nil || info.Mode().Perm()&^0644 != 0 {
+		t.Fatalf("mode = %v, err = %v", info.Mode(), err)
+	}
+
+	for _, tt := range []struct {
+		start, end int64
+		want       string
+	}{
+		{0, -1, "0123456789"},
+		{2, 4, "234"},
+		{7, -1, "789"},
+		{8, 20, "89"},
+	} {
+		r, err := c.GetObjectReader("a/b.txt", standard.Range(tt.start, tt.end))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(r)
+		_ = r.Close()
+		if string(b) != tt.want {
+			t.Fatalf("range %d-%d = %q, want %q", tt.start, tt.end, b, tt.want)
+		}
+	}
+	if _, err := c.GetObjectReader("a/b.txt", standard.Range(5, 1)); err == nil {
+		t.Fatal("invalid range should fail")
+	}
+
+	if err := c.CopyObject("a/b.txt", "c.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.MoveObject("c.txt", "d/e.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(c.path("c.txt")); !os.IsNotExist(err) {
+		t.Fatalf("moved source still exists: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(c.path("d/e.txt")); string(b) != "0123456789" {
+		t.Fatalf("moved object = %q", b)
+	}
+	if c.path("../../etc/passwd") != filepath.Join(c.bucket, "etc", "passwd") {
+		t.Fatalf("path escaped bucket: %s", c.path("../../etc/passwd"))
+	}
+}
+
+func TestUploadFileResume(t *testing.T) {
+	c, dir := newTestOss(t)
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	src := filepath.Join(dir, "src.bin")
+	if err := ioutil.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟第 3 个分片完成后进程中断
+	func() {
+		defer func() { _ = recover() }()
+		_ = c.UploadFile("big.bin", src, 128, standard.Checkpoint(true, ""), standard.Progress(func(consumed, total int64) {
+			if consumed == 3*128 {
+				panic("interrupted")
+			}
+		}))
+	}()
+	if _, err := os.Stat(src + ".cp"); err != nil {
+		t.Fatalf("checkpoint not saved: %v", err)
+	}
+
+	var first int64 = -1
+	err := c.UploadFile("big.bin", src, 128, standard.Checkpoint(true, ""), standard.Progress(func(consumed, total int64) {
+		if first < 0 {
+			first = consumed
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 4*128 {
+		t.Fatalf("resumed from %d, want %d", first-128, 3*128)
+	}
+	if b, _ := ioutil.ReadFile(c.path("big.bin")); !bytes.Equal(b, data) {
+		t.Fatal("uploaded content mismatch")
+	}
+	if _, err := os.Stat(src + ".cp"); !os.IsNotExist(err) {
+		t.Fatal("checkpoint should be removed after upload")
+	}
+}
+
+func TestObjectMeta(t *testing.T) {
+	c, dir := newTestOss(t)
+	if err := c.PutObject("/docs/a.json", strings.NewReader(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutObject("docs/b", strings.NewReader("hello"), standard.ContentType("text/plain")); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.IsObjectExist("docs/a.json"); !ok || err != nil {
+		t.Fatalf("exist = %v, %v", ok, err)
+	}
+	if b, err := c.GetObject("/docs/b"); err != nil || string(b) != "hello" {
+		t.Fatalf("get = %q, %v", b, err)
+	}
+	local := filepath.Join(dir, "local", "b.txt")
+	if err := c.GetObjectToFile("docs/b", local); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(local); string(b) != "hello" {
+		t.Fatalf("local file = %q", b)
+	}
+
+	a, _ := c.stat("docs/a.json")
+	b, _ := c.stat("docs/b")
+	if a.ContentType != "application/json" || b.ContentType != "text/plain" {
+		t.Fatalf("content type = %s, %s", a.ContentType, b.ContentType)
+	}
+	if b.ETag != `"5D41402ABC4B2A76B9719D911017C592"` {
+		t.Fatalf("etag = %s", b.ETag)
+	}
+	if err := c.CopyObject("docs/b", "docs/c"); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := c.stat("docs/c"); m.ContentType != "text/plain" || m.ETag != b.ETag {
+		t.Fatalf("copied meta = %+v", m)
+	}
+
+	res, err := c.DeleteObjects([]string{"docs/a.json", "docs/b", "docs/missing"})
+	if err != nil || len(res.DeletedObjects) != 3 {
+		t.Fatalf("delete = %+v, %v", res, err)
+	}
+	if _, err := os.Stat(c.metaPath("docs/b")); !os.IsNotExist(err) {
+		t.Fatal("meta should be removed with object")
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	c, _ := newTestOss(t)
+	for _, k := range []string{"a.txt", "a/1", "a/2", "a/b/3", "b/4", "c"} {
+		if err := c.PutObject(k, strings.NewReader(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keysOf := func(res standard.ListObjectsResult) []string {
+		var keys []string
+		for _, o := range res.Objects {
+			keys = append(keys, o.Key)
+		}
+		return keys
+	}
+
+	res, err := c.ListObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(keysOf(res), ","); got != "a.txt,a/1,a/2,a/b/3,b/4,c" {
+		t.Fatalf("keys = %s", got)
+	}
+	if res.Objects[0].Size != 5 || res.Objects[0].ETag == "" || res.Objects[0].LastModified.IsZero() {
+		t.Fatalf("object = %+v", res.Objects[0])
+	}
+
+	res, _ = c.ListObjects(standard.Delimiter("/"))
+	if got := strings.Join(keysOf(res), ","); got != "a.txt,c" || strings.Join(res.CommonPrefixes, ",") != "a/,b/" {
+		t.Fatalf("keys = %s, prefixes = %v", got, res.CommonPrefixes)
+	}
+
+	res, _ = c.ListObjects(standard.Prefix("a/"), standard.Delimiter("/"))
+	if got := strings.Join(keysOf(res), ","); got != "a/1,a/2" || strings.Join(res.CommonPrefixes, ",") != "a/b/" {
+		t.Fatalf("keys = %s, prefixes = %v", got, res.CommonPrefixes)
+	}
+
+	// 分页
+	var all []string
+	marker := ""
+	for {
+		res, err = c.ListObjects(standard.Delimiter("/"), standard.Marker(marker), standard.MaxKeys(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, keysOf(res)...)
+		all = append(all, res.CommonPrefixes...)
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+	if got := strings.Join(all, ","); got != "a.txt,a/,b/,c" {
+		t.Fatalf("pages = %s", got)
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	c, _ := newTestOss(t)
+	srv := httptest.NewServer(http.StripPrefix("/files", c))
+	defer srv.Close()
+	c.cdnName = srv.URL + "/files"
+
+	putURL, err := c.SignURL("dir/hello world.txt", http.MethodPut, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, putURL, strings.NewReader("hello"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+		t.Fatalf("put status = %d", resp.StatusCode)
+	}
+
+	getURL, _ := c.SignURL("dir/hello world.txt", http.MethodGet, 60)
+	req, _ = http.NewRequest(http.MethodGet, getURL, nil)
+	req.Header.Set("Range", "bytes=1-3")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(b) != "ell" || resp.Header.Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Fatalf("get status = %d, body = %q, type = %s", resp.StatusCode, b, resp.Header.Get("Content-Type"))
+	}
+
+	for name, u := range map[string]string{
+		"get with put signature": putURL,
+		"tampered signature":     strings.Replace(getURL, "Signature=", "Signature=x", 1),
+		"unsigned":               c.cdnName + "/dir/hello%20world.txt",
+	} {
+		resp, err = http.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("%s: status = %d", name, resp.StatusCode)
+		}
+	}
+
+	expired := c.cdnName + "/dir/hello%20world.txt?Expires=1&Signature=" + c.sign(http.MethodGet, "1", "dir/hello world.txt")
+	resp, _ = http.Get(expired)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expired: status = %d", resp.StatusCode)
+	}
+
+	missing, _ := c.SignURL("missing", http.MethodGet, 60)
+	resp, _ = http.Get(missing)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing: status = %d", resp.StatusCode)
+	}
+
+	// 元数据和临时文件不签名，即使签名正确也不能访问
+	for _, k := range []string{metaDir + "/dir/hello.txt.json", "dir/.x.upload", "dir/.x.tmp123"} {
+		if _, err := c.SignURL(k, http.MethodPut, 60); err != errReservedKey {
+			t.Fatalf("%s: sign err = %v", k, err)
+		}
+		expires := strconv.FormatInt(time.Now().Unix()+60, 10)
+		q := url.Values{expiresParam: {expires}, signatureParam: {c.sign(http.MethodPut, expires, k)}}
+		req, _ = http.NewRequest(http.MethodPut, c.cdnName+"/"+k+"?"+q.Encode(), strings.NewReader("{}"))
+		resp, _ = http.DefaultClient.Do(req)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("%s: status = %d", k, resp.StatusCode)
+		}
+	}
+
+	// 未配置 secret 时拒绝所有请求
+	c.secret = ""
+	getURL, _ = c.SignURL("dir/hello world.txt", http.MethodGet, 60)
+	resp, _ = http.Get(getURL)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("no secret: status = %d", resp.StatusCode)
+	}
+}
+
+func TestReservedKey(t *testing.T) {
+	c, _ := newTestOss(t)
+	if err := c.PutObject("dir/a.txt", strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{metaDir + "/dir/a.txt.json", "/" + metaDir, "dir/.a.txt.upload", "dir/../dir/.a.txt.tmp1"} {
+		if err := c.PutObject(k, strings.NewReader("{}")); err != errReservedKey {
+			t.Fatalf("%s: put err = %v", k, err)
+		}
+		if _, err := c.GetObject(k); err != errReservedKey {
+			t.Fatalf("%s: get err = %v", k, err)
+		}
+		if _, err := c.GetObjectReader(k); err != errReservedKey {
+			t.Fatalf("%s: get reader err = %v", k, err)
+		}
+		if err := c.CopyObject("dir/a.txt", k); err != errReservedKey {
+			t.Fatalf("%s: copy err = %v", k, err)
+		}
+		if err := c.MoveObject(k, "dir/b.txt"); err != errReservedKey {
+			t.Fatalf("%s: move from err = %v", k, err)
+		}
+		if err := c.MoveObject("dir/a.txt", k); err != errReservedKey {
+			t.Fatalf("%s: move to err = %v", k, err)
+		}
+		if err := c.DeleteObject(k); err != errReservedKey {
+			t.Fatalf("%s: delete err = %v", k, err)
+		}
+		if out, err := c.DeleteObjects([]string{k}); err != errReservedKey || len(out.DeletedObjects) != 0 {
+			t.Fatalf("%s: delete objects = %v, err = %v", k, out.DeletedObjects, err)
+		}
+		if _, err := c.IsObjectExist(k); err != errReservedKey {
+			t.Fatalf("%s: exist err = %v", k, err)
+		}
+	}
+
+	// 元数据不受影响，对象仍可读取
+	if _, err := os.Stat(c.metaPath("dir/a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := c.GetObject("dir/a.txt"); err != nil || string(b) != "hello" {
+		t.Fatalf("get = %q, err = %v", b, err)
+	}
+}
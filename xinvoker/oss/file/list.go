@@ -0,0 +1,119 @@
+package file
+
+import (
+	"github.com/coder2z/g-server/xinvoker/oss/standard"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultMaxKeys = 100
+	maxMaxKeys     = 1000
+)
+
+// ListObjects 与 OSS 一致，按对象名字典序返回大于 Marker 的对象，Objects 和 CommonPrefixes 合计不超过 MaxKeys
+func (c *Client) ListObjects(options ...standard.Option) (standard.ListObjectsResult, error) {
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return standard.ListObjectsResult{}, err
+	}
+	if opts.MaxKeys == 0 {
+		opts.MaxKeys = defaultMaxKeys
+	}
+	if opts.MaxKeys > maxMaxKeys {
+		opts.MaxKeys = maxMaxKeys
+	}
+	result := standard.ListObjectsResult{
+		Prefix:    opts.Prefix,
+		Marker:    opts.Marker,
+		MaxKeys:   opts.MaxKeys,
+		Delimiter: opts.Delimiter,
+	}
+
+	keys, err := c.keys(opts.Prefix)
+	if err != nil {
+		return result, err
+	}
+
+	var last string
+	for _, k := range keys {
+		if k <= opts.Marker || !strings.HasPrefix(k, opts.Prefix) {
+			continue
+		}
+		var commonPrefix string
+		if opts.Delimiter != "" {
+			if i := strings.Index(k[len(opts.Prefix):], opts.Delimiter); i >= 0 {
+				commonPrefix = k[:len(opts.Prefix)+i+len(opts.Delimiter)]
+				if commonPrefix == last || commonPrefix <= opts.Marker {
+					continue
+				}
+			}
+		}
+		if len(result.Objects)+len(result.CommonPrefixes) == opts.MaxKeys {
+			result.IsTruncated = true
+			result.NextMarker = last
+			break
+		}
+		if commonPrefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+			last = commonPrefix
+			continue
+		}
+		m, err := c.stat(k)
+		if os.IsNotExist(err) { // 列出后被删除
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Objects = append(result.Objects, standard.ObjectProperties{
+			Key:          k,
+			Type:         "Normal",
+			Size:         m.Size,
+			ETag:         m.ETag,
+			LastModified: m.LastModified,
+			StorageClass: "Standard",
+		})
+		last = k
+	}
+	return result, nil
+}
+
+// keys 按字典序返回 prefix 所在目录下的所有对象名
+func (c *Client) keys(prefix string) ([]string, error) {
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = key(prefix[:i])
+	}
+	root := filepath.Join(c.bucket, filepath.FromSlash(dir))
+
+	var keys []string
+	err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(c.bucket, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if hidden(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			keys = append(keys, path.Clean(rel))
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
@@ -0,0 +1,106 @@
+package file
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// metaDir bucket 下保存元数据的目录，每个对象对应一个 {key}.json
+const metaDir = ".xoss-meta"
+
+const defaultContentType = "application/octet-stream"
+
+type meta struct {
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+func (c *Client) metaPath(dstPath string) string {
+	return filepath.Join(c.bucket, metaDir, filepath.FromSlash(key(dstPath))+".json")
+}
+
+// writeMeta 未指定 contentType 时按扩展名推断
+func (c *Client) writeMeta(dstPath, contentType, etag string) error {
+	info, err := os.Stat(c.path(dstPath))
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = contentTypeByExt(dstPath)
+	}
+	b, err := json.Marshal(meta{ContentType: contentType, ETag: etag, Size: info.Size(), LastModified: info.ModTime()})
+	if err != nil {
+		return err
+	}
+	name := c.metaPath(dstPath)
+	if err = os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, b, 0644)
+}
+
+// stat 读取对象元数据；直接放入 bucket 目录或元数据已过期的文件，按文件内容重新计算
+func (c *Client) stat(dstPath string) (meta, error) {
+	info, err := os.Stat(c.path(dstPath))
+	if err != nil {
+		return meta{}, err
+	}
+	if info.IsDir() {
+		return meta{}, &os.PathError{Op: "stat", Path: c.path(dstPath), Err: os.ErrNotExist}
+	}
+	var m meta
+	if b, err := ioutil.ReadFile(c.metaPath(dstPath)); err == nil && json.Unmarshal(b, &m) == nil &&
+		m.Size == info.Size() && m.LastModified.Equal(info.ModTime()) {
+		return m, nil
+	}
+
+	m = meta{ContentType: contentTypeByExt(dstPath), Size: info.Size(), LastModified: info.ModTime()}
+	if m.ETag, err = fileETag(c.path(dstPath)); err != nil {
+		return meta{}, err
+	}
+	return m, nil
+}
+
+func (c *Client) moveMeta(srcPath, dstPath string) error {
+	name := c.metaPath(dstPath)
+	if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		return err
+	}
+	err := os.Rename(c.metaPath(srcPath), name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *Client) removeMeta(dstPath string) error {
+	err := os.Remove(c.metaPath(dstPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func contentTypeByExt(dstPath string) string {
+	if t := mime.TypeByExtension(path.Ext(dstPath)); t != "" {
+		return t
+	}
+	return defaultContentType
+}
+
+// hidden 元数据目录和写入过程中的临时文件
+func hidden(rel string) bool {
+	if rel == metaDir || strings.HasPrefix(rel, metaDir+"/") {
+		return true
+	}
+	name := path.Base(rel)
+	return strings.HasPrefix(name, ".") && (strings.Contains(name, ".tmp") || strings.HasSuffix(name, uploadSuffix))
+}
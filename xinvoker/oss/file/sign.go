@@ -0,0 +1,155 @@
+package file
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"github.com/coder2z/g-server/xinvoker/oss/standard"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	expiresParam   = "Expires"
+	signatureParam = "Signature"
+)
+
+// SignURL 生成 {cdnName}/{key}?Expires=&Signature= 形式的地址，签名包含请求方法、过期时间和对象名；
+// 未配置 secret 时返回不带签名的地址，需要由 cdnName 指向的静态服务提供访问，ServeHTTP 不接受这类地址
+func (c *Client) SignURL(dstPath string, method string, expiredInSec int64, options ...standard.Option) (resp string, err error) {
+	if expiredInSec <= 0 {
+		return "", errors.New("expired seconds must be greater than 0")
+	}
+	if err = checkKey(dstPath); err != nil {
+		return
+	}
+	k := key(dstPath)
+	segments := strings.Split(k, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	resp = strings.TrimRight(c.cdnName, "/") + "/" + strings.Join(segments, "/")
+	if c.secret == "" {
+		return
+	}
+	expires := strconv.FormatInt(time.Now().Unix()+expiredInSec, 10)
+	q := url.Values{}
+	q.Set(expiresParam, expires)
+	q.Set(signatureParam, c.sign(strings.ToUpper(method), expires, k))
+	return resp + "?" + q.Encode(), nil
+}
+
+func (c *Client) sign(method, expires, k string) string {
+	mac := hmac.New(sha256.New, []byte(c.secret))
+	mac.Write([]byte(method + "\n" + expires + "\n/" + k))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验签名和过期时间，HEAD 请求可以使用 GET 的签名
+func (c *Client) verify(r *http.Request, k string) (code, msg string) {
+	q := r.URL.Query()
+	expires, signature := q.Get(expiresParam), q.Get(signatureParam)
+	if expires == "" || signature == "" {
+		return "AccessDenied", "Signature required"
+	}
+	sec, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "AccessDenied", "Invalid expires"
+	}
+	if time.Now().Unix() > sec {
+		return "AccessDenied", "Request has expired"
+	}
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if !hmac.Equal([]byte(signature), []byte(c.sign(method, expires, k))) {
+		return "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided"
+	}
+	return "", ""
+}
+
+// ServeHTTP 提供 SignURL 生成的地址，支持 GET、HEAD 和 PUT，GET 支持 Range 和 If-None-Match；
+// 未配置 secret 时拒绝所有请求，元数据目录和写入中的临时文件不能通过该接口访问；
+// 挂载到非根路径时需配合 http.StripPrefix 使用，如
+// http.Handle("/oss/", http.StripPrefix("/oss", xoss.Invoker("main").(http.Handler)))
+func (c *Client) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.secret == "" {
+		writeError(w, http.StatusForbidden, "AccessDenied", "Signature secret not configured")
+		return
+	}
+	k := key(r.URL.Path)
+	if k == "" {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", "Object key required")
+		return
+	}
+	if hidden(k) {
+		writeError(w, http.StatusForbidden, "AccessDenied", "Reserved object key")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed")
+		return
+	}
+	if code, msg := c.verify(r, k); code != "" {
+		writeError(w, http.StatusForbidden, code, msg)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		err := c.PutObject(k, r.Body, standard.ContentType(r.Header.Get("Content-Type")))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+			return
+		}
+		m, err := c.stat(k)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+			return
+		}
+		w.Header().Set("ETag", m.ETag)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	m, err := c.stat(k)
+	if os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	f, err := os.Open(c.path(k))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", m.ContentType)
+	w.Header().Set("ETag", m.ETag)
+	http.ServeContent(w, r, "", m.LastModified, f)
+}
+
+type errorResponse struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// writeError 与 OSS 相同的 XML 错误格式
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(errorResponse{Code: code, Message: msg})
+}
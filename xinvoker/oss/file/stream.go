@@ -0,0 +1,211 @@
+package file
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/coder2z/g-server/xinvoker/oss/standard"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 写入过程中的临时文件，以 . 开头，ListObjects 时忽略
+const (
+	tmpSuffix    = ".tmp"
+	uploadSuffix = ".upload"
+)
+
+// fileMode 对象文件的权限，与 ioutil.WriteFile 一样受 umask 限制
+const fileMode = 0644
+
+// createTemp 在 dir 下创建以 prefix 开头的临时文件，ioutil.TempFile 固定使用 0600，这里使用 fileMode
+func createTemp(dir, prefix string) (*os.File, error) {
+	for {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, fileMode)
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+}
+
+// writeFile 先写入同目录的临时文件再重命名，写入失败不会留下不完整的对象，返回内容的 ETag
+func writeFile(dst string, r io.Reader) (etag string, err error) {
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return
+	}
+	f, err := createTemp(filepath.Dir(dst), "."+filepath.Base(dst)+tmpSuffix)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	h := md5.New()
+	if _, err = io.Copy(io.MultiWriter(f, h), r); err != nil {
+		return
+	}
+	if err = f.Close(); err != nil {
+		return
+	}
+	return formatETag(h.Sum(nil)), os.Rename(f.Name(), dst)
+}
+
+// fileETag 与 OSS 普通上传一致，使用内容 MD5 的大写十六进制
+func fileETag(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return formatETag(h.Sum(nil)), nil
+}
+
+func formatETag(sum []byte) string {
+	return `"` + strings.ToUpper(hex.EncodeToString(sum)) + `"`
+}
+
+// checkpoint 分片上传的断点记录，源文件变化后失效
+type checkpoint struct {
+	Dst       string `json:"dst"`
+	Size      int64  `json:"size"`
+	ModTime   int64  `json:"mod_time"`
+	PartSize  int64  `json:"part_size"`
+	Completed int64  `json:"completed"` // 已完成的字节数
+}
+
+type upload struct {
+	dst      string
+	src      string
+	tmp      string
+	cpPath   string
+	partSize int64
+	opts     standard.Options
+}
+
+func newUpload(dst, src string, partSize int64, opts standard.Options) *upload {
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+uploadSuffix)
+	u := &upload{dst: dst, src: src, tmp: tmp, partSize: partSize, opts: opts}
+	if opts.Checkpoint {
+		u.cpPath = opts.CheckpointPath
+		if u.cpPath == "" {
+			u.cpPath = src + ".cp"
+		}
+	}
+	return u
+}
+
+func (u *upload) run() (err error) {
+	src, err := os.Open(u.src)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(u.dst), os.ModePerm); err != nil {
+		return
+	}
+
+	cp := checkpoint{Dst: u.dst, Size: info.Size(), ModTime: info.ModTime().UnixNano(), PartSize: u.partSize}
+	cp.Completed = u.resume(cp)
+
+	tmp, err := os.OpenFile(u.tmp, os.O_CREATE|os.O_WRONLY, fileMode)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if tmp != nil {
+			_ = tmp.Close()
+		}
+	}()
+	if err = tmp.Truncate(cp.Completed); err != nil {
+		return
+	}
+	if _, err = tmp.Seek(cp.Completed, io.SeekStart); err != nil {
+		return
+	}
+	if _, err = src.Seek(cp.Completed, io.SeekStart); err != nil {
+		return
+	}
+
+	for cp.Completed < cp.Size {
+		n := u.partSize
+		if left := cp.Size - cp.Completed; left < n {
+			n = left
+		}
+		if _, err = io.CopyN(tmp, src, n); err != nil {
+			return
+		}
+		cp.Completed += n
+		if u.cpPath != "" {
+			if err = tmp.Sync(); err != nil {
+				return
+			}
+			if err = u.save(cp); err != nil {
+				return
+			}
+		}
+		if u.opts.Progress != nil {
+			u.opts.Progress(cp.Completed, cp.Size)
+		}
+	}
+
+	err = tmp.Close()
+	tmp = nil
+	if err != nil {
+		return
+	}
+	if err = os.Rename(u.tmp, u.dst); err != nil {
+		return
+	}
+	if u.cpPath != "" {
+		_ = os.Remove(u.cpPath)
+	}
+	return
+}
+
+// resume 断点有效时返回已完成的字节数
+func (u *upload) resume(cp checkpoint) int64 {
+	if u.cpPath == "" {
+		return 0
+	}
+	b, err := ioutil.ReadFile(u.cpPath)
+	if err != nil {
+		return 0
+	}
+	var saved checkpoint
+	if err = json.Unmarshal(b, &saved); err != nil {
+		return 0
+	}
+	if saved.Dst != cp.Dst || saved.Size != cp.Size || saved.ModTime != cp.ModTime || saved.PartSize != cp.PartSize {
+		return 0
+	}
+	info, err := os.Stat(u.tmp)
+	if err != nil || info.Size() < saved.Completed {
+		return 0
+	}
+	return saved.Completed
+}
+
+func (u *upload) save(cp checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(u.cpPath, b, fileMode)
+}
@@ -1,8 +1,10 @@
 package xoss
 
 import (
+	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xinvoker"
 	"github.com/coder2z/g-server/xinvoker/oss/standard"
 	"sync"
@@ -10,6 +12,9 @@ import (
 
 var ossI *ossInvoker
 
+// probeKey 健康检查时查询的对象，不需要存在
+const probeKey = ".xhealth"
+
 func Register(k string) xinvoker.Invoker {
 	ossI = &ossInvoker{key: k}
 	return ossI
@@ -36,18 +41,30 @@ type ossInvoker struct {
 func (i *ossInvoker) Init(opts ...xinvoker.Option) error {
 	i.instances = sync.Map{}
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.new(cfg))
+		i.store(name, i.new(cfg))
 	}
 	return nil
 }
 
 func (i *ossInvoker) Reload(opts ...xinvoker.Option) error {
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.new(cfg))
+		i.store(name, i.new(cfg))
 	}
 	return nil
 }
 
+func (i *ossInvoker) store(name string, c standard.Oss) {
+	i.instances.Store(name, c)
+	xhealth.RegisterInvoker(i.key, name, func(ctx context.Context) error {
+		_, err := c.IsObjectExist(probeKey)
+		return err
+	}, xhealth.NonCritical())
+}
+
 func (i *ossInvoker) Close(opts ...xinvoker.Option) error {
+	i.instances.Range(func(key, value interface{}) bool {
+		xhealth.DeregisterInvoker(i.key, key.(string))
+		return true
+	})
 	return nil
 }
@@ -1,15 +1,20 @@
 package xoss
 
+import "time"
+
 type options struct {
-	Debug           bool   `mapStructure:"debug"`
-	Mode            string `mapStructure:"mode"`
-	Addr            string `mapStructure:"addr"`
-	AccessKeyID     string `mapStructure:"accessKeyId"`
-	AccessKeySecret string `mapStructure:"accessKeySecret"`
-	CdnName         string `mapStructure:"cdnName"`
-	OssBucket       string `mapStructure:"ossBucket"`
-	FileBucket      string `mapStructure:"fileBucket"`
-	IsDeleteSrcPath bool   `mapStructure:"isDeleteSrcPath"`
+	Debug           bool          `mapStructure:"debug"`
+	Mode            string        `mapStructure:"mode"`
+	Addr            string        `mapStructure:"addr"`
+	AccessKeyID     string        `mapStructure:"accessKeyId"`
+	AccessKeySecret string        `mapStructure:"accessKeySecret"` // file 模式下用于 SignURL 签名
+	CdnName         string        `mapStructure:"cdnName"`
+	OssBucket       string        `mapStructure:"ossBucket"`
+	FileBucket      string        `mapStructure:"fileBucket"`
+	IsDeleteSrcPath bool          `mapStructure:"isDeleteSrcPath"`
+	Region          string        `mapStructure:"region"`    // s3 模式的区域，默认 us-east-1
+	PathStyle       bool          `mapStructure:"pathStyle"` // s3 模式使用 {addr}/{bucket}/{key} 访问，MinIO 通常需要开启
+	Timeout         time.Duration `mapStructure:"timeout"`   // s3 模式建立连接和等待响应头的超时，默认 10s
 }
 
 func newOssOptions() *options {
@@ -23,5 +28,8 @@ func newOssOptions() *options {
 		OssBucket:       "",
 		FileBucket:      ".",
 		IsDeleteSrcPath: false,
+		Region:          "",
+		PathStyle:       false,
+		Timeout:         0,
 	}
 }
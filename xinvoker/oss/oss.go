@@ -6,6 +6,7 @@ import (
 	"github.com/coder2z/g-saber/xlog"
 	"github.com/coder2z/g-server/xinvoker/oss/alioss"
 	"github.com/coder2z/g-server/xinvoker/oss/file"
+	"github.com/coder2z/g-server/xinvoker/oss/s3"
 	"github.com/coder2z/g-server/xinvoker/oss/standard"
 )
 
@@ -25,8 +26,10 @@ func (i *ossInvoker) new(o *options) (client standard.Oss) {
 	switch o.Mode {
 	case "aliOss":
 		client, err = alioss.NewOss(o.Addr, o.AccessKeyID, o.AccessKeySecret, o.OssBucket, o.IsDeleteSrcPath)
+	case "s3":
+		client, err = s3.NewOss(o.Addr, o.AccessKeyID, o.AccessKeySecret, o.OssBucket, o.Region, o.PathStyle, o.IsDeleteSrcPath, o.Timeout)
 	case "file":
-		client, err = file.NewOss(o.CdnName, o.FileBucket, o.IsDeleteSrcPath)
+		client, err = file.NewOss(o.CdnName, o.FileBucket, o.AccessKeySecret, o.IsDeleteSrcPath)
 	default:
 		err = errors.New("oss mode not exist")
 	}
@@ -0,0 +1,327 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fakeObject struct {
+	data         []byte
+	contentType  string
+	etag         string
+	lastModified time.Time
+}
+
+type fakeUpload struct {
+	key         string
+	contentType string
+	parts       map[int][]byte
+}
+
+// fakeS3 内存中的 S3 服务，只支持 path-style，校验 SigV4 签名
+type fakeS3 struct {
+	signer *signer
+	bucket string
+
+	mu       sync.Mutex
+	objects  map[string]fakeObject
+	uploads  map[string]*fakeUpload
+	seq      int
+	parts    int          // 收到的 UploadPart 请求数
+	failPart map[int]bool // 第一次上传这些分片时返回 500
+}
+
+func newFakeS3(bucket, accessKeyID, accessKeySecret string) *fakeS3 {
+	return &fakeS3{
+		signer:   &signer{accessKeyID: accessKeyID, accessKeySecret: accessKeySecret, region: defaultRegion},
+		bucket:   bucket,
+		objects:  make(map[string]fakeObject),
+		uploads:  make(map[string]*fakeUpload),
+		failPart: make(map[int]bool),
+	}
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	if code, msg := s.verify(r, body); code != "" {
+		s.error(w, http.StatusForbidden, code, msg)
+		return
+	}
+	prefix := "/" + s.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path+"/", prefix) {
+		s.error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	k := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+s.bucket), "/")
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case k == "" && r.Method == http.MethodGet:
+		s.list(w, q)
+	case k == "" && r.Method == http.MethodPost && has(q, "delete"):
+		s.deleteObjects(w, r, body)
+	case r.Method == http.MethodPost && has(q, "uploads"):
+		s.seq++
+		id := "upload-" + strconv.Itoa(s.seq)
+		s.uploads[id] = &fakeUpload{key: k, contentType: r.Header.Get("Content-Type"), parts: make(map[int][]byte)}
+		s.xml(w, initiateMultipartUploadResult{Bucket: s.bucket, Key: k, UploadID: id})
+	case r.Method == http.MethodPut && has(q, "uploadId"):
+		s.uploadPart(w, q, body)
+	case r.Method == http.MethodPost && has(q, "uploadId"):
+		s.complete(w, k, q, body)
+	case r.Method == http.MethodDelete && has(q, "uploadId"):
+		delete(s.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get(copySourceHeader) != "":
+		s.copy(w, r, k)
+	case r.Method == http.MethodPut:
+		s.put(k, body, r.Header.Get("Content-Type"))
+		w.Header().Set("ETag", s.objects[k].etag)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, k)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		o, ok := s.objects[k]
+		if !ok {
+			s.error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", o.contentType)
+		w.Header().Set("ETag", o.etag)
+		http.ServeContent(w, r, "", o.lastModified, bytes.NewReader(o.data))
+	default:
+		s.error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// verify 按服务端收到的请求重新计算签名
+func (s *fakeS3) verify(r *http.Request, body []byte) (code, msg string) {
+	q := r.URL.Query()
+	var (
+		date, credential, signature, payloadHash string
+		signed                                   []string
+	)
+	if q.Get("X-Amz-Signature") != "" {
+		date, credential, signature = q.Get("X-Amz-Date"), q.Get("X-Amz-Credential"), q.Get("X-Amz-Signature")
+		signed, payloadHash = strings.Split(q.Get("X-Amz-SignedHeaders"), ";"), unsignedPayload
+		t, _ := time.Parse(timeFormat, date)
+		expires, _ := strconv.Atoi(q.Get("X-Amz-Expires"))
+		if time.Now().After(t.Add(time.Duration(expires) * time.Second)) {
+			return "AccessDenied", "Request has expired"
+		}
+	} else {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, algorithm+" ") {
+			return "AccessDenied", "Anonymous access is forbidden"
+		}
+		for _, kv := range strings.Split(strings.TrimPrefix(auth, algorithm+" "), ", ") {
+			parts := strings.SplitN(kv, "=", 2)
+			switch parts[0] {
+			case "Credential":
+				credential = parts[1]
+			case "SignedHeaders":
+				signed = strings.Split(parts[1], ";")
+			case "Signature":
+				signature = parts[1]
+			}
+		}
+		date, payloadHash = r.Header.Get(headerDate), r.Header.Get(headerContentSHA256)
+		if payloadHash != unsignedPayload && payloadHash != hashHex(body) {
+			return "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed."
+		}
+	}
+	if md5Header := r.Header.Get("Content-Md5"); md5Header != "" {
+		sum := md5.Sum(body)
+		if md5Header != base64.StdEncoding.EncodeToString(sum[:]) {
+			return "BadDigest", "The Content-MD5 you specified did not match what we received."
+		}
+	}
+	t, err := time.Parse(timeFormat, date)
+	if err != nil {
+		return "AccessDenied", "Invalid date"
+	}
+	if credential != s.signer.accessKeyID+"/"+s.signer.scope(t) {
+		return "InvalidAccessKeyId", credential
+	}
+	header := r.Header.Clone()
+	header.Set("Host", r.Host)
+	if signature != s.signer.signature(r.Method, r.URL.EscapedPath(), q, header, signed, payloadHash, t) {
+		return "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."
+	}
+	return "", ""
+}
+
+func (s *fakeS3) put(k string, data []byte, contentType string) {
+	if contentType == "" {
+		contentType = "binary/octet-stream"
+	}
+	sum := md5.Sum(data)
+	s.objects[k] = fakeObject{data: data, contentType: contentType, etag: `"` + hex.EncodeToString(sum[:]) + `"`, lastModified: time.Now().UTC().Truncate(time.Second)}
+}
+
+func (s *fakeS3) list(w http.ResponseWriter, q url.Values) {
+	prefix, marker, delimiter := q.Get("prefix"), q.Get("marker"), q.Get("delimiter")
+	maxKeys := 1000
+	if v := q.Get("max-keys"); v != "" {
+		maxKeys, _ = strconv.Atoi(v)
+	}
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := listBucketResult{Name: s.bucket, Prefix: prefix, Marker: marker, MaxKeys: maxKeys, Delimiter: delimiter}
+	var last string
+	for _, k := range keys {
+		if k <= marker || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		cp := ""
+		if delimiter != "" {
+			if i := strings.Index(k[len(prefix):], delimiter); i >= 0 {
+				cp = k[:len(prefix)+i+len(delimiter)]
+				if cp == last || cp <= marker {
+					continue
+				}
+			}
+		}
+		if len(out.Contents)+len(out.CommonPrefixes) == maxKeys {
+			out.IsTruncated = true
+			if delimiter != "" {
+				out.NextMarker = last
+			}
+			break
+		}
+		if cp != "" {
+			out.CommonPrefixes = append(out.CommonPrefixes, commonPrefix{Prefix: cp})
+			last = cp
+			continue
+		}
+		o := s.objects[k]
+		out.Contents = append(out.Contents, object{Key: k, LastModified: o.lastModified, ETag: o.etag, Size: int64(len(o.data)), StorageClass: "STANDARD"})
+		last = k
+	}
+	s.xml(w, out)
+}
+
+func (s *fakeS3) deleteObjects(w http.ResponseWriter, r *http.Request, body []byte) {
+	if r.Header.Get("Content-Md5") == "" {
+		s.error(w, http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: Content-Md5.")
+		return
+	}
+	var in deleteRequest
+	if err := xml.Unmarshal(body, &in); err != nil {
+		s.error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	var out deleteResult
+	for _, o := range in.Objects {
+		if strings.HasPrefix(o.Key, "locked/") {
+			out.Errors = append(out.Errors, Error{Code: "AccessDenied", Message: "Access Denied", Key: o.Key})
+			continue
+		}
+		delete(s.objects, o.Key)
+		out.Deleted = append(out.Deleted, o)
+	}
+	s.xml(w, out)
+}
+
+func (s *fakeS3) uploadPart(w http.ResponseWriter, q url.Values, body []byte) {
+	u, ok := s.uploads[q.Get("uploadId")]
+	if !ok {
+		s.error(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	n, _ := strconv.Atoi(q.Get("partNumber"))
+	s.parts++
+	if s.failPart[n] {
+		delete(s.failPart, n)
+		s.error(w, http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.")
+		return
+	}
+	u.parts[n] = body
+	sum := md5.Sum(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+}
+
+func (s *fakeS3) complete(w http.ResponseWriter, k string, q url.Values, body []byte) {
+	u, ok := s.uploads[q.Get("uploadId")]
+	if !ok || u.key != k {
+		s.error(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	var in completeMultipartUpload
+	if err := xml.Unmarshal(body, &in); err != nil {
+		s.error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	var data []byte
+	for i, p := range in.Parts {
+		part, ok := u.parts[p.PartNumber]
+		sum := md5.Sum(part)
+		if !ok || p.PartNumber != i+1 || p.ETag != `"`+hex.EncodeToString(sum[:])+`"` {
+			// 与 S3 一致，状态码为 200，错误在响应内容中
+			s.xml(w, Error{Code: "InvalidPart", Message: fmt.Sprintf("part %d", p.PartNumber)})
+			return
+		}
+		data = append(data, part...)
+	}
+	delete(s.uploads, q.Get("uploadId"))
+	s.put(k, data, u.contentType)
+	s.xml(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Key     string   `xml:"Key"`
+	}{Key: k})
+}
+
+func (s *fakeS3) copy(w http.ResponseWriter, r *http.Request, k string) {
+	src, _ := url.PathUnescape(r.Header.Get(copySourceHeader))
+	src = strings.TrimPrefix(src, "/"+s.bucket+"/")
+	o, ok := s.objects[src]
+	if !ok {
+		s.error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	contentType := o.contentType
+	if r.Header.Get(directiveHeader) == "REPLACE" {
+		contentType = r.Header.Get("Content-Type")
+	}
+	s.put(k, o.data, contentType)
+	s.xml(w, copyObjectResult{ETag: s.objects[k].etag, LastModified: s.objects[k].lastModified})
+}
+
+func (s *fakeS3) xml(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func (s *fakeS3) error(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Error
+	}{Error: Error{Code: code, Message: msg}})
+}
+
+func has(q url.Values, k string) bool {
+	_, ok := q[k]
+	return ok
+}
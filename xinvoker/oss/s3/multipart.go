@@ -0,0 +1,294 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/coder2z/g-server/xinvoker/oss/standard"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// checkpoint 分片上传的断点记录，源文件变化后失效
+type checkpoint struct {
+	Bucket   string         `json:"bucket"`
+	Key      string         `json:"key"`
+	Size     int64          `json:"size"`
+	ModTime  int64          `json:"mod_time"`
+	PartSize int64          `json:"part_size"`
+	UploadID string         `json:"upload_id"`
+	Parts    []completePart `json:"parts"` // 已完成的分片
+}
+
+func (cp checkpoint) match(o checkpoint) bool {
+	return cp.Bucket == o.Bucket && cp.Key == o.Key && cp.Size == o.Size && cp.ModTime == o.ModTime && cp.PartSize == o.PartSize
+}
+
+// UploadFile 分片上传本地文件，partSize 为 5MiB~5GiB；开启 Checkpoint 时中断后从已完成的分片继续，
+// 否则失败时取消本次分片上传
+func (c *Client) UploadFile(dstPath, srcPath string, partSize int64, options ...standard.Option) (err error) {
+	if partSize < minPartSize || partSize > maxPartSize {
+		return fmt.Errorf("part size must be between %d and %d", minPartSize, int64(maxPartSize))
+	}
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return
+	}
+	cpPath := ""
+	if opts.Checkpoint {
+		cpPath = opts.CheckpointPath
+		if cpPath == "" {
+			cpPath = srcPath + ".cp"
+		}
+	}
+
+	err = c.uploadFile(key(dstPath), srcPath, cpPath, partSize, opts)
+	var e *Error
+	if errors.As(err, &e) && e.Code == "NoSuchUpload" && cpPath != "" { // 断点中的 uploadId 已过期，重新上传
+		_ = os.Remove(cpPath)
+		err = c.uploadFile(key(dstPath), srcPath, cpPath, partSize, opts)
+	}
+	if err != nil {
+		return
+	}
+	if c.isDelete {
+		err = os.Remove(srcPath)
+	}
+	return
+}
+
+func (c *Client) uploadFile(k, srcPath, cpPath string, partSize int64, opts standard.Options) error {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= partSize {
+		var reader io.Reader = f
+		if opts.Progress != nil {
+			reader = standard.ProgressReader(f, info.Size(), opts.Progress)
+		}
+		return c.putObject(k, reader, info.Size(), opts)
+	}
+	total := (info.Size() + partSize - 1) / partSize
+	if total > maxParts {
+		return fmt.Errorf("too many parts %d, increase part size", total)
+	}
+
+	cp := checkpoint{Bucket: c.bucket, Key: k, Size: info.Size(), ModTime: info.ModTime().UnixNano(), PartSize: partSize}
+	if saved, ok := loadCheckpoint(cpPath); ok && saved.match(cp) {
+		cp = saved
+	} else {
+		if cp.UploadID, err = c.initiate(k, opts); err != nil {
+			return err
+		}
+		if err = saveCheckpoint(cpPath, cp); err != nil {
+			return err
+		}
+	}
+
+	done := make(map[int]bool, len(cp.Parts))
+	var consumed int64
+	for _, p := range cp.Parts {
+		done[p.PartNumber] = true
+		consumed += partLen(cp.Size, partSize, p.PartNumber)
+	}
+	jobs := make(chan int, total)
+	for n := 1; n <= int(total); n++ {
+		if !done[n] {
+			jobs <- n
+		}
+	}
+	close(jobs)
+
+	uploadID, size := cp.UploadID, cp.Size
+	routines := opts.Routines
+	if routines < 1 {
+		routines = 1
+	}
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < routines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					return
+				}
+
+				n64 := partLen(size, partSize, n)
+				etag, err := c.uploadPart(k, uploadID, n, io.NewSectionReader(f, int64(n-1)*partSize, n64), n64)
+
+				mu.Lock()
+				if err == nil {
+					cp.Parts = append(cp.Parts, completePart{PartNumber: n, ETag: etag})
+					err = saveCheckpoint(cpPath, cp)
+					consumed += n64
+					if opts.Progress != nil {
+						opts.Progress(consumed, size)
+					}
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		if cpPath == "" {
+			_ = c.abort(k, cp.UploadID)
+		}
+		return firstErr
+	}
+	if err = c.complete(k, cp.UploadID, cp.Parts); err != nil {
+		return err
+	}
+	if cpPath != "" {
+		_ = os.Remove(cpPath)
+	}
+	return nil
+}
+
+// putStream 按分片读取并上传，不超过一个分片时直接上传；分片大小为 minPartSize，
+// 已知长度时放大到不超过 maxParts 个分片
+func (c *Client) putStream(k string, reader io.Reader, length int64, opts standard.Options) error {
+	partSize := minPartSize
+	if n := (length + maxParts - 1) / maxParts; n > partSize {
+		partSize = n
+	}
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(reader, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return c.putObject(k, bytes.NewReader(buf[:n]), int64(n), opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	uploadID, err := c.initiate(k, opts)
+	if err != nil {
+		return err
+	}
+	var parts []completePart
+	for partNumber := 1; n > 0; partNumber++ {
+		if partNumber > maxParts {
+			err = fmt.Errorf("too many parts %d", partNumber)
+			break
+		}
+		var etag string
+		if etag, err = c.uploadPart(k, uploadID, partNumber, bytes.NewReader(buf[:n]), int64(n)); err != nil {
+			break
+		}
+		parts = append(parts, completePart{PartNumber: partNumber, ETag: etag})
+		if n < len(buf) {
+			break
+		}
+		n, err = io.ReadFull(reader, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+		} else if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		_ = c.abort(k, uploadID)
+		return err
+	}
+	return c.complete(k, uploadID, parts)
+}
+
+func (c *Client) initiate(k string, opts standard.Options) (string, error) {
+	header := http.Header{}
+	contentType(header, k, opts.ContentType)
+	resp, err := c.do(request{method: http.MethodPost, key: k, query: url.Values{"uploads": {""}}, header: header})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var out initiateMultipartUploadResult
+	if err = decodeBody(resp, k, &out); err != nil {
+		return "", err
+	}
+	if out.UploadID == "" {
+		return "", errors.New("s3: empty upload id")
+	}
+	return out.UploadID, nil
+}
+
+func (c *Client) uploadPart(k, uploadID string, partNumber int, body io.Reader, size int64) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	resp, err := c.do(request{method: http.MethodPut, key: k, query: query, body: body, length: size})
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *Client) complete(k, uploadID string, parts []completePart) error {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return c.doXML(http.MethodPost, k, url.Values{"uploadId": {uploadID}}, completeMultipartUpload{Parts: parts}, nil)
+}
+
+func (c *Client) abort(k, uploadID string) error {
+	resp, err := c.do(request{method: http.MethodDelete, key: k, query: url.Values{"uploadId": {uploadID}}})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// partLen 第 n 个分片的长度，最后一个分片可能小于 partSize
+func partLen(size, partSize int64, n int) int64 {
+	if left := size - int64(n-1)*partSize; left < partSize {
+		return left
+	}
+	return partSize
+}
+
+func loadCheckpoint(name string) (checkpoint, bool) {
+	var cp checkpoint
+	if name == "" {
+		return cp, false
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return cp, false
+	}
+	if err = json.Unmarshal(b, &cp); err != nil {
+		return cp, false
+	}
+	return cp, cp.UploadID != ""
+}
+
+func saveCheckpoint(name string, cp checkpoint) error {
+	if name == "" {
+		return nil
+	}
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, b, 0644)
+}
@@ -0,0 +1,471 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"github.com/coder2z/g-server/xinvoker/oss/standard"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRegion = "us-east-1"
+
+	maxDeleteObjects = 1000                // DeleteObjects 单次请求最多删除的对象数
+	maxPresignExpire = 7 * 24 * 60 * 60    // 预签名地址最长有效期，单位秒
+	maxErrorBody     = 1 << 20             // 读取错误响应的最大长度
+	maxParts         = 10000               // 最多 10000 个分片
+	copySourceHeader = "X-Amz-Copy-Source" // CopyObject 源对象
+	directiveHeader  = "X-Amz-Metadata-Directive"
+
+	// defaultTimeout 建立连接和等待响应头的默认超时，不限制 body 的传输时间，避免大文件上传下载被中断
+	defaultTimeout = 10 * time.Second
+)
+
+var (
+	// minPartSize 除最后一个分片外每个分片至少 5MiB，也是长度未知的流式上传的缓冲区大小
+	minPartSize int64 = 5 << 20
+	// maxPartSize 分片最大 5GiB，也是单次 PUT 的长度上限
+	maxPartSize int64 = 5 << 30
+)
+
+// Client 兼容 S3 API 的对象存储，如 AWS S3、MinIO、Ceph RGW 等；
+// pathStyle 为 true 时使用 {endpoint}/{bucket}/{key}，否则使用 {bucket}.{endpoint}/{key}
+type Client struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	isDelete  bool
+	signer    *signer
+	client    *http.Client
+}
+
+// NewOss endpoint 未指定协议时使用 https，region 为空时使用 us-east-1；
+// timeout 为建立连接和等待响应头的超时，为 0 时使用 defaultTimeout
+func NewOss(endpoint, accessKeyID, accessKeySecret, bucket, region string, pathStyle, isDelete bool, timeout time.Duration) (client standard.Oss, err error) {
+	if bucket == "" {
+		return nil, errors.New("s3 bucket required")
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, errors.New("s3 endpoint required")
+	}
+	if region == "" {
+		region = defaultRegion
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client = &Client{
+		endpoint:  &url.URL{Scheme: u.Scheme, Host: u.Host},
+		bucket:    bucket,
+		pathStyle: pathStyle,
+		isDelete:  isDelete,
+		signer:    &signer{accessKeyID: accessKeyID, accessKeySecret: accessKeySecret, region: region},
+		client:    &http.Client{Transport: newTransport(timeout)},
+	}
+	return
+}
+
+func newTransport(timeout time.Duration) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	t.TLSHandshakeTimeout = timeout
+	t.ResponseHeaderTimeout = timeout
+	return t
+}
+
+// key S3 对象名不以 / 开头
+func key(dstPath string) string {
+	return strings.TrimLeft(dstPath, "/")
+}
+
+// url 对象地址，key 为空时为 bucket 地址
+func (c *Client) url(k string, query url.Values) *url.URL {
+	u := *c.endpoint
+	p := "/" + k
+	if c.pathStyle {
+		p = "/" + c.bucket + p
+	} else {
+		u.Host = c.bucket + "." + u.Host
+	}
+	u.Path, u.RawPath = p, escape(p, false)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+type request struct {
+	method      string
+	key         string
+	query       url.Values
+	header      http.Header
+	body        io.Reader
+	length      int64  // body 长度，未知时为 -1
+	payloadHash string // 为空时不签名 body
+}
+
+// do 状态码不是 2xx 时返回 *Error，调用方负责关闭 Body
+func (c *Client) do(r request) (*http.Response, error) {
+	u := c.url(r.key, r.query)
+	req, err := http.NewRequest(r.method, u.String(), r.body)
+	if err != nil {
+		return nil, err
+	}
+	req.URL, req.Host = u, u.Host
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	switch {
+	case r.body == nil || r.length == 0:
+		req.Body, req.GetBody, req.ContentLength = http.NoBody, nil, 0
+	case r.length > 0:
+		req.ContentLength = r.length
+	}
+	payloadHash := r.payloadHash
+	if r.body == nil && payloadHash == "" {
+		payloadHash = emptySHA256
+	}
+	c.signer.sign(req, payloadHash, time.Now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		return nil, parseError(resp, r.key)
+	}
+	return resp, nil
+}
+
+// doXML 发送 XML 请求体并解析 XML 响应，out 为 nil 时忽略响应内容
+func (c *Client) doXML(method, k string, query url.Values, in, out interface{}) error {
+	var r request
+	r.method, r.key, r.query, r.header = method, k, query, http.Header{}
+	if in != nil {
+		b, err := xml.Marshal(in)
+		if err != nil {
+			return err
+		}
+		sum := md5.Sum(b)
+		r.header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+		r.header.Set("Content-Type", "application/xml")
+		r.body, r.length, r.payloadHash = bytes.NewReader(b), int64(len(b)), hashHex(b)
+	}
+	resp, err := c.do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeBody(resp, k, out)
+}
+
+// decodeBody CopyObject 和 CompleteMultipartUpload 可能在返回 200 后才失败，错误在响应内容中
+func decodeBody(resp *http.Response, k string, out interface{}) error {
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	if xml.Unmarshal(b, &root) == nil && root.XMLName.Local == "Error" {
+		e := &Error{StatusCode: resp.StatusCode, Key: k}
+		if err = xml.Unmarshal(b, e); err == nil {
+			return e
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	return xml.Unmarshal(b, out)
+}
+
+func parseError(resp *http.Response, k string) error {
+	e := &Error{StatusCode: resp.StatusCode, Key: k}
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if len(b) == 0 || xml.Unmarshal(b, e) != nil {
+		e.Code = strings.Replace(http.StatusText(resp.StatusCode), " ", "", -1)
+	}
+	if e.Key == "" {
+		e.Key = k
+	}
+	return e
+}
+
+// contentType 未指定时按扩展名推断
+func contentType(header http.Header, dstPath, value string) {
+	if value == "" {
+		value = mime.TypeByExtension(path.Ext(dstPath))
+	}
+	if value != "" {
+		header.Set("Content-Type", value)
+	}
+}
+
+// PutObject 无法得知 reader 长度或长度超过单次 PUT 上限时，自动使用分片上传，内存占用不超过一个分片
+func (c *Client) PutObject(dstPath string, reader io.Reader, options ...standard.Option) error {
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return err
+	}
+	length := standard.ReaderLen(reader)
+	if opts.Progress != nil {
+		reader = standard.ProgressReader(reader, length, opts.Progress)
+	}
+	if length < 0 || length > maxPartSize {
+		return c.putStream(key(dstPath), reader, length, opts)
+	}
+	return c.putObject(key(dstPath), reader, length, opts)
+}
+
+func (c *Client) putObject(k string, reader io.Reader, length int64, opts standard.Options) error {
+	header := http.Header{}
+	contentType(header, k, opts.ContentType)
+	resp, err := c.do(request{method: http.MethodPut, key: k, header: header, body: reader, length: length})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *Client) PutObjectFromFile(dstPath, srcPath string, options ...standard.Option) (err error) {
+	var f *os.File
+	f, err = os.Open(srcPath)
+	if err != nil {
+		return
+	}
+	err = c.PutObject(dstPath, f, options...)
+	_ = f.Close()
+	if err != nil {
+		return
+	}
+	if c.isDelete {
+		err = os.Remove(srcPath)
+	}
+	return
+}
+
+func (c *Client) GetObject(dstPath string, options ...standard.Option) (output []byte, err error) {
+	var reader io.ReadCloser
+	reader, err = c.GetObjectReader(dstPath, options...)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// GetObjectToFile 下载对象到本地文件 srcPath，先写入临时文件，完成后重命名
+func (c *Client) GetObjectToFile(dstPath, srcPath string, options ...standard.Option) (err error) {
+	reader, err := c.GetObjectReader(dstPath, options...)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	tmp := tempPath(srcPath)
+	f, err := os.Create(tmp)
+	if err != nil {
+		return
+	}
+	if _, err = io.Copy(f, reader); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return
+	}
+	if err = f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	return os.Rename(tmp, srcPath)
+}
+
+func (c *Client) GetObjectReader(dstPath string, options ...standard.Option) (io.ReadCloser, error) {
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if r := opts.Range; r != nil {
+		end := ""
+		if r.End >= 0 {
+			end = strconv.FormatInt(r.End, 10)
+		}
+		header.Set("Range", "bytes="+strconv.FormatInt(r.Start, 10)+"-"+end)
+	}
+	resp, err := c.do(request{method: http.MethodGet, key: key(dstPath), header: header})
+	if err != nil {
+		return nil, err
+	}
+	body := resp.Body
+	if opts.Progress != nil {
+		body = standard.ReadCloser(standard.ProgressReader(body, resp.ContentLength, opts.Progress), body)
+	}
+	return body, nil
+}
+
+// CopyObject 指定 ContentType 时替换目标对象的元数据，否则沿用源对象的
+func (c *Client) CopyObject(srcPath, dstPath string, options ...standard.Option) error {
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set(copySourceHeader, escape("/"+c.bucket+"/"+key(srcPath), false))
+	if opts.ContentType != "" {
+		header.Set(directiveHeader, "REPLACE")
+		header.Set("Content-Type", opts.ContentType)
+	}
+	resp, err := c.do(request{method: http.MethodPut, key: key(dstPath), header: header})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeBody(resp, key(dstPath), &copyObjectResult{})
+}
+
+// MoveObject S3 不支持重命名，复制成功后删除源对象
+func (c *Client) MoveObject(srcPath, dstPath string, options ...standard.Option) error {
+	if err := c.CopyObject(srcPath, dstPath, options...); err != nil {
+		return err
+	}
+	return c.DeleteObject(srcPath)
+}
+
+func (c *Client) DeleteObject(dstPath string) error {
+	resp, err := c.do(request{method: http.MethodDelete, key: key(dstPath)})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DeleteObjects 每 1000 个对象一次请求，部分对象删除失败时返回第一个错误，DeletedObjects 为成功删除的对象
+func (c *Client) DeleteObjects(dstPaths []string, options ...standard.Option) (output standard.DeleteObjectsResult, err error) {
+	output.Local = "DeleteResult"
+	for len(dstPaths) > 0 {
+		n := len(dstPaths)
+		if n > maxDeleteObjects {
+			n = maxDeleteObjects
+		}
+		in := deleteRequest{}
+		for _, p := range dstPaths[:n] {
+			in.Objects = append(in.Objects, objectIdentifier{Key: key(p)})
+		}
+		dstPaths = dstPaths[n:]
+
+		var out deleteResult
+		if err = c.doXML(http.MethodPost, "", url.Values{"delete": {""}}, in, &out); err != nil {
+			return
+		}
+		for _, o := range out.Deleted {
+			output.DeletedObjects = append(output.DeletedObjects, o.Key)
+		}
+		if len(out.Errors) > 0 && err == nil {
+			e := out.Errors[0]
+			e.StatusCode = http.StatusOK
+			err = &e
+		}
+	}
+	return
+}
+
+func (c *Client) IsObjectExist(dstPath string) (bool, error) {
+	resp, err := c.do(request{method: http.MethodHead, key: key(dstPath)})
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, resp.Body.Close()
+}
+
+// ListObjects 使用 ListObjects(V1)，未指定 Delimiter 时 S3 不返回 NextMarker，使用最后一个对象名
+func (c *Client) ListObjects(options ...standard.Option) (standard.ListObjectsResult, error) {
+	opts, err := standard.ParseOptions(options)
+	if err != nil {
+		return standard.ListObjectsResult{}, err
+	}
+	query := url.Values{}
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Marker != "" {
+		query.Set("marker", opts.Marker)
+	}
+	if opts.MaxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(opts.MaxKeys))
+	}
+	if opts.Delimiter != "" {
+		query.Set("delimiter", opts.Delimiter)
+	}
+
+	var out listBucketResult
+	if err = c.doXML(http.MethodGet, "", query, nil, &out); err != nil {
+		return standard.ListObjectsResult{}, err
+	}
+	result := standard.ListObjectsResult{
+		XMLName:     out.XMLName,
+		Prefix:      out.Prefix,
+		Marker:      out.Marker,
+		MaxKeys:     out.MaxKeys,
+		Delimiter:   out.Delimiter,
+		IsTruncated: out.IsTruncated,
+		NextMarker:  out.NextMarker,
+	}
+	for _, o := range out.Contents {
+		result.Objects = append(result.Objects, standard.ObjectProperties{
+			Key:          o.Key,
+			Size:         o.Size,
+			ETag:         o.ETag,
+			Owner:        standard.Owner{ID: o.Owner.ID, DisplayName: o.Owner.DisplayName},
+			LastModified: o.LastModified,
+			StorageClass: o.StorageClass,
+		})
+		if result.IsTruncated && out.NextMarker == "" && o.Key > result.NextMarker {
+			result.NextMarker = o.Key
+		}
+	}
+	for _, p := range out.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, p.Prefix)
+		if result.IsTruncated && out.NextMarker == "" && p.Prefix > result.NextMarker {
+			result.NextMarker = p.Prefix
+		}
+	}
+	return result, nil
+}
+
+// SignURL 预签名地址，有效期最长 7 天
+func (c *Client) SignURL(dstPath string, method string, expiredInSec int64, options ...standard.Option) (string, error) {
+	if expiredInSec <= 0 || expiredInSec > maxPresignExpire {
+		return "", errors.New("expired seconds must be between 1 and 604800")
+	}
+	return c.signer.presign(strings.ToUpper(method), c.url(key(dstPath), nil), time.Duration(expiredInSec)*time.Second, time.Now()), nil
+}
+
+// tempPath 与目标文件同目录的临时文件
+func tempPath(name string) string {
+	return filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+}
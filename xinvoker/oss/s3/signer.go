@@ -0,0 +1,175 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	service         = "s3"
+	timeFormat      = "20060102T150405Z"
+	dateFormat      = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	emptySHA256     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	headerDate          = "X-Amz-Date"
+	headerContentSHA256 = "X-Amz-Content-Sha256"
+)
+
+// signer AWS Signature Version 4
+type signer struct {
+	accessKeyID     string
+	accessKeySecret string
+	region          string
+}
+
+// sign 签名请求头，payloadHash 为空时使用 UNSIGNED-PAYLOAD，流式上传不需要预先计算内容摘要
+func (s *signer) sign(req *http.Request, payloadHash string, t time.Time) {
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+	}
+	t = t.UTC()
+	req.Header.Set(headerDate, t.Format(timeFormat))
+	req.Header.Set(headerContentSHA256, payloadHash)
+
+	header := req.Header.Clone()
+	header.Set("Host", req.URL.Host)
+	signed := make([]string, 0, len(header))
+	for k := range header {
+		signed = append(signed, strings.ToLower(k))
+	}
+	sort.Strings(signed)
+
+	signature := s.signature(req.Method, req.URL.EscapedPath(), req.URL.Query(), header, signed, payloadHash, t)
+	req.Header.Set("Authorization", algorithm+" Credential="+s.accessKeyID+"/"+s.scope(t)+
+		", SignedHeaders="+strings.Join(signed, ";")+", Signature="+signature)
+}
+
+// presign 生成预签名地址，只签名 host
+func (s *signer) presign(method string, u *url.URL, expires time.Duration, t time.Time) string {
+	t = t.UTC()
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", algorithm)
+	q.Set("X-Amz-Credential", s.accessKeyID+"/"+s.scope(t))
+	q.Set("X-Amz-Date", t.Format(timeFormat))
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	header := http.Header{"Host": {u.Host}}
+	signature := s.signature(method, u.EscapedPath(), q, header, []string{"host"}, unsignedPayload, t)
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(q) + "&X-Amz-Signature=" + signature
+	return signed.String()
+}
+
+func (s *signer) scope(t time.Time) string {
+	return t.Format(dateFormat) + "/" + s.region + "/" + service + "/aws4_request"
+}
+
+// signature 计算签名，query 中的 X-Amz-Signature 不参与签名
+func (s *signer) signature(method, path string, query url.Values, header http.Header, signed []string, payloadHash string, t time.Time) string {
+	var headers strings.Builder
+	for _, k := range signed {
+		headers.WriteString(k)
+		headers.WriteByte(':')
+		values := header.Values(k)
+		for i, v := range values {
+			if i > 0 {
+				headers.WriteByte(',')
+			}
+			headers.WriteString(strings.Join(strings.Fields(v), " "))
+		}
+		headers.WriteByte('\n')
+	}
+	q := make(url.Values, len(query))
+	for k, v := range query {
+		if k != "X-Amz-Signature" {
+			q[k] = v
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery(q),
+		headers.String(),
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		algorithm,
+		t.Format(timeFormat),
+		s.scope(t),
+		hashHex([]byte(canonical)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.accessKeySecret), t.Format(dateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQuery 按参数名排序并按 RFC 3986 编码，同时用作实际发送的 RawQuery
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(escape(k, true))
+			b.WriteByte('=')
+			b.WriteString(escape(v, true))
+		}
+	}
+	return b.String()
+}
+
+// escape 除 RFC 3986 非保留字符外全部编码，对象名中的 / 不编码
+func escape(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexChars[c>>4])
+		b.WriteByte(hexChars[c&15])
+	}
+	return b.String()
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,182 @@
+package standard
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	HTTPHeaderContentType = "Content-Type"
+
+	prefixParam    = "prefix"
+	markerParam    = "marker"
+	maxKeysParam   = "max-keys"
+	delimiterParam = "delimiter"
+
+	rangeArg         = "x-range"
+	progressListener = "x-progress-listener"
+	routineNum       = "x-routine-num"
+	checkpointConfig = "x-cp-config"
+)
+
+// ByteRange 读取对象的字节区间，Start 和 End 都包含在内，End 小于 0 时读到末尾
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// ProgressFunc 上传或下载进度回调，totalBytes 未知时为 -1
+type ProgressFunc func(consumedBytes, totalBytes int64)
+
+type cpConfig struct {
+	IsEnable bool
+	FilePath string
+}
+
+// Options 解析后的选项，供各存储实现读取
+type Options struct {
+	ContentType    string
+	Range          *ByteRange
+	Progress       ProgressFunc
+	Routines       int    // 分片并发数，file 模式忽略
+	Checkpoint     bool   // 断点续传
+	CheckpointPath string // 断点记录文件，为空时使用 {srcPath}.cp
+
+	// ListObjects 使用
+	Prefix    string
+	Marker    string
+	MaxKeys   int
+	Delimiter string
+}
+
+// ContentType 对象的 Content-Type
+func ContentType(value string) Option {
+	return setHeader(HTTPHeaderContentType, value)
+}
+
+// Range 只读取 [start, end] 区间，end 小于 0 时读到末尾
+func Range(start, end int64) Option {
+	return func(params map[string]optionValue) error {
+		if start < 0 || (end >= 0 && end < start) {
+			return fmt.Errorf("invalid range %d-%d", start, end)
+		}
+		params[rangeArg] = optionValue{Value: ByteRange{Start: start, End: end}, Type: optionArg}
+		return nil
+	}
+}
+
+// Progress 上传或下载的进度回调
+func Progress(fn ProgressFunc) Option {
+	if fn == nil {
+		return nil
+	}
+	return addArg(progressListener, fn)
+}
+
+// Routines 分片上传的并发数
+func Routines(n int) Option {
+	return func(params map[string]optionValue) error {
+		if n < 1 {
+			return errors.New("routines must be greater than 0")
+		}
+		params[routineNum] = optionValue{Value: n, Type: optionArg}
+		return nil
+	}
+}
+
+// Checkpoint 分片上传时记录已完成的分片，中断后以相同参数重新调用即可从断点继续
+func Checkpoint(isEnable bool, filePath string) Option {
+	return addArg(checkpointConfig, cpConfig{IsEnable: isEnable, FilePath: filePath})
+}
+
+// Prefix 只列出以 value 开头的对象
+func Prefix(value string) Option {
+	return addParam(prefixParam, value)
+}
+
+// Marker 从大于 value 的对象开始列出，通常使用上一次结果的 NextMarker
+func Marker(value string) Option {
+	return addParam(markerParam, value)
+}
+
+// MaxKeys 单次最多返回的对象和公共前缀数量
+func MaxKeys(value int) Option {
+	return func(params map[string]optionValue) error {
+		if value < 1 {
+			return errors.New("max keys must be greater than 0")
+		}
+		params[maxKeysParam] = optionValue{Value: value, Type: optionParam}
+		return nil
+	}
+}
+
+// Delimiter 按 value 对对象名分组，第一个 value 之前的部分相同的对象合并为 CommonPrefixes
+func Delimiter(value string) Option {
+	return addParam(delimiterParam, value)
+}
+
+func addParam(key string, value interface{}) Option {
+	return func(params map[string]optionValue) error {
+		params[key] = optionValue{Value: value, Type: optionParam}
+		return nil
+	}
+}
+
+func setHeader(key string, value interface{}) Option {
+	return func(params map[string]optionValue) error {
+		params[key] = optionValue{Value: value, Type: optionHTTP}
+		return nil
+	}
+}
+
+func addArg(key string, value interface{}) Option {
+	return func(params map[string]optionValue) error {
+		params[key] = optionValue{Value: value, Type: optionArg}
+		return nil
+	}
+}
+
+// ParseOptions 解析选项，同名选项以最后一个为准
+func ParseOptions(options []Option) (Options, error) {
+	params := make(map[string]optionValue)
+	for _, option := range options {
+		if option == nil {
+			continue
+		}
+		if err := option(params); err != nil {
+			return Options{}, err
+		}
+	}
+
+	var o Options
+	if v, ok := params[HTTPHeaderContentType]; ok {
+		o.ContentType, _ = v.Value.(string)
+	}
+	if v, ok := params[prefixParam]; ok {
+		o.Prefix = v.Value.(string)
+	}
+	if v, ok := params[markerParam]; ok {
+		o.Marker = v.Value.(string)
+	}
+	if v, ok := params[maxKeysParam]; ok {
+		o.MaxKeys = v.Value.(int)
+	}
+	if v, ok := params[delimiterParam]; ok {
+		o.Delimiter = v.Value.(string)
+	}
+	if v, ok := params[rangeArg]; ok {
+		r := v.Value.(ByteRange)
+		o.Range = &r
+	}
+	if v, ok := params[progressListener]; ok {
+		o.Progress = v.Value.(ProgressFunc)
+	}
+	if v, ok := params[routineNum]; ok {
+		o.Routines = v.Value.(int)
+	}
+	if v, ok := params[checkpointConfig]; ok {
+		cp := v.Value.(cpConfig)
+		o.Checkpoint, o.CheckpointPath = cp.IsEnable, cp.FilePath
+	}
+	return o, nil
+}
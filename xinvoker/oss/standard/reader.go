@@ -0,0 +1,56 @@
+package standard
+
+import (
+	"io"
+	"os"
+)
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// ReadCloser 读取 r，关闭时关闭 c
+func ReadCloser(r io.Reader, c io.Closer) io.ReadCloser {
+	return readCloser{Reader: r, Closer: c}
+}
+
+type progressReader struct {
+	io.Reader
+	consumed int64
+	total    int64
+	fn       ProgressFunc
+}
+
+// ProgressReader 每次读取后回调 fn，total 未知时为 -1
+func ProgressReader(r io.Reader, total int64, fn ProgressFunc) io.Reader {
+	return &progressReader{Reader: r, total: total, fn: fn}
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		r.consumed += int64(n)
+		r.fn(r.consumed, r.total)
+	}
+	return
+}
+
+// ReaderLen 剩余可读的长度，无法得知时返回 -1
+func ReaderLen(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
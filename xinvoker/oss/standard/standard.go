@@ -67,6 +67,12 @@ type Oss interface {
 	PutObjectFromFile(dstPath, srcPath string, options ...Option) error
 	GetObject(dstPath string, options ...Option) ([]byte, error)
 	GetObjectToFile(dstPath, srcPath string, options ...Option) error
+	// GetObjectReader 流式读取对象，支持 Range，调用方负责 Close
+	GetObjectReader(dstPath string, options ...Option) (io.ReadCloser, error)
+	// UploadFile 按 partSize 分片上传本地文件，支持 Routines、Checkpoint 和 Progress
+	UploadFile(dstPath, srcPath string, partSize int64, options ...Option) error
+	CopyObject(srcPath, dstPath string, options ...Option) error
+	MoveObject(srcPath, dstPath string, options ...Option) error
 	DeleteObject(dstPath string) error
 	DeleteObjects(dstPaths []string, options ...Option) (DeleteObjectsResult, error)
 	IsObjectExist(dstPath string) (bool, error)
@@ -1,8 +1,10 @@
 package xredis
 
 import (
+	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xinvoker"
 	"github.com/go-redis/redis/v8"
 	"sync"
@@ -36,20 +38,28 @@ type redisInvoker struct {
 func (i *redisInvoker) Init(opts ...xinvoker.Option) error {
 	i.instances = sync.Map{}
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.newRedisClient(cfg))
+		i.store(name, i.newRedisClient(cfg))
 	}
 	return nil
 }
 
 func (i *redisInvoker) Reload(opts ...xinvoker.Option) error {
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.newRedisClient(cfg))
+		i.store(name, i.newRedisClient(cfg))
 	}
 	return nil
 }
 
+func (i *redisInvoker) store(name string, c *redis.Client) {
+	i.instances.Store(name, c)
+	xhealth.RegisterInvoker(i.key, name, func(ctx context.Context) error {
+		return c.Ping(ctx).Err()
+	})
+}
+
 func (i *redisInvoker) Close(opts ...xinvoker.Option) error {
 	i.instances.Range(func(key, value interface{}) bool {
+		xhealth.DeregisterInvoker(i.key, key.(string))
 		_ = value.(*redis.Client).Close()
 		i.instances.Delete(key)
 		return true
@@ -1,8 +1,10 @@
 package xsms
 
 import (
+	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xinvoker"
 	"sync"
 )
@@ -35,20 +37,28 @@ type smsInvoker struct {
 func (i *smsInvoker) Init(opts ...xinvoker.Option) error {
 	i.instances = sync.Map{}
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.newSMSClient(cfg))
+		i.store(name, i.newSMSClient(cfg))
 	}
 	return nil
 }
 
 func (i *smsInvoker) Reload(opts ...xinvoker.Option) error {
 	for name, cfg := range i.loadConfig() {
-		i.instances.Store(name, i.newSMSClient(cfg))
+		i.store(name, i.newSMSClient(cfg))
 	}
 	return nil
 }
 
+func (i *smsInvoker) store(name string, c *Client) {
+	i.instances.Store(name, c)
+	xhealth.RegisterInvoker(i.key, name, func(ctx context.Context) error {
+		return c.Ping(ctx)
+	}, xhealth.NonCritical())
+}
+
 func (i *smsInvoker) Close(opts ...xinvoker.Option) error {
 	i.instances.Range(func(key, value interface{}) bool {
+		xhealth.DeregisterInvoker(i.key, key.(string))
 		i.instances.Delete(key)
 		return true
 	})
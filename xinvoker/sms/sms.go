@@ -1,12 +1,16 @@
 package xsms
 
 import (
+	"context"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/dysmsapi"
 	"github.com/coder2z/g-saber/xcfg"
 	"github.com/coder2z/g-saber/xlog"
+	"net"
 )
 
+const endpoint = "dysmsapi.aliyuncs.com:443"
+
 type (
 	SmsResponse = dysmsapi.SendSmsResponse
 	SmsRequest  = dysmsapi.SendSmsRequest
@@ -40,6 +44,16 @@ func (i *smsInvoker) loadConfig() map[string]*options {
 	return conf
 }
 
+// Ping 只建立到短信接口的 TCP 连接，不发送短信，供健康检查使用
+func (ali *Client) Ping(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 func (ali *Client) Send(req *SmsRequest) (*SmsResponse, error) {
 	if req.RpcRequest == nil {
 		req.RpcRequest = new(requests.RpcRequest)
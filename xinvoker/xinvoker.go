@@ -43,16 +43,20 @@ func Register(ivk ...Invoker) {
 	invokers = append(invokers, ivk...)
 }
 
-// Init invoker执行初始化具体实现
+// Init invoker执行初始化具体实现，失败时关闭已经初始化的 invoker
 func Init(opts ...Option) error {
-	for _, invoker := range invokers {
+	for i, invoker := range invokers {
 		key := reflect.ValueOf(invoker).Elem().FieldByName("key").String()
 		xlog.Info("Application Starting",
 			xlog.FieldComponentName("XInvoker"),
 			xlog.FieldMethod("XInvoker.Init"),
 			xlog.FieldDescription(fmt.Sprintf("Invoker start running initialization:%s", key)),
 		)
-		_ = invoker.Init(opts...)
+		if err := invoker.Init(opts...); err != nil {
+			// 失败的 invoker 可能已经创建了部分实例，一并关闭
+			closeInvokers(invokers[:i+1], opts...)
+			return fmt.Errorf("invoker %s init: %w", key, err)
+		}
 	}
 
 	return nil
@@ -75,15 +79,19 @@ func Reload(opts ...Option) error {
 
 // Close invoker执行退出具体实现
 func Close(opts ...Option) error {
-	for i := len(invokers) - 1; i >= 0; i-- {
-		key := reflect.ValueOf(invokers[i]).Elem().FieldByName("key").String()
+	closeInvokers(invokers, opts...)
+	return nil
+}
+
+// closeInvokers 按注册的相反顺序关闭
+func closeInvokers(ivks []Invoker, opts ...Option) {
+	for i := len(ivks) - 1; i >= 0; i-- {
+		key := reflect.ValueOf(ivks[i]).Elem().FieldByName("key").String()
 		xlog.Info("Application Stopping",
 			xlog.FieldComponentName("XInvoker"),
 			xlog.FieldMethod("XInvoker.Close"),
 			xlog.FieldDescription(fmt.Sprintf("Invoker start running close:%s", key)),
 		)
-		_ = invokers[i].Close(opts...)
+		_ = ivks[i].Close(opts...)
 	}
-
-	return nil
 }
@@ -0,0 +1,94 @@
+package xlogger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type payloadInfo struct {
+	Enabled   bool       `json:"enabled"`
+	Override  bool       `json:"override"` // false 表示使用拦截器自身的配置
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// LevelHttp GET 查看全局级别；POST ?level=debug&ttl=10m 临时调整；DELETE 立即恢复；
+// xlog 不支持按组件过滤，带 component 参数时返回 400
+func LevelHttp(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("component") != "" {
+		writeError(w, http.StatusBadRequest, errComponentLevel)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		ttl, err := parseTTL(q.Get("ttl"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if _, err := SetLevel(q.Get("level"), ttl); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	case http.MethodDelete:
+		ResetLevel()
+	default:
+		writeError(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, Level())
+}
+
+// PayloadHttp GET 查看；POST ?enable=true&ttl=10m 临时开启或关闭 gRPC 请求和响应内容日志；DELETE 恢复配置
+func PayloadHttp(w http.ResponseWriter, r *http.Request) {
+	var expiresAt *time.Time
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		enable, err := strconv.ParseBool(r.URL.Query().Get("enable"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		ttl, err := parseTTL(r.URL.Query().Get("ttl"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		t := SetPayload(enable, ttl)
+		expiresAt = &t
+	case http.MethodDelete:
+		ResetPayload()
+	default:
+		writeError(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+	enabled, ok := Payload()
+	writeJSON(w, http.StatusOK, payloadInfo{Enabled: enabled, Override: ok, ExpiresAt: expiresAt})
+}
+
+func parseTTL(s string) (time.Duration, error) {
+	if s == "" {
+		return DefaultTTL, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	msg := http.StatusText(status)
+	if err != nil {
+		msg = err.Error()
+	}
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,122 @@
+package xlogger
+
+import (
+	"errors"
+	"github.com/coder2z/g-saber/xcfg"
+	"github.com/coder2z/g-saber/xlog"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTTL 未指定 TTL 时临时调整的有效期，到期后自动恢复
+const DefaultTTL = 10 * time.Minute
+
+// errComponentLevel xlog 未提供替换 core 的选项，无法按 compName 过滤日志
+var errComponentLevel = errors.New("component level is not supported by xlog")
+
+// LevelInfo 当前生效的全局日志级别
+type LevelInfo struct {
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 临时调整的到期时间，为空表示配置中的级别
+}
+
+// level 临时调整时用 xlog.StdConfig().Build(xlog.WithLevel(...)) 创建对应级别的 logger 替换默认 logger，
+// 到期后换回调整前的默认 logger；已经通过 xlog.DefaultLogger().With 保存的 logger 不受影响
+var level struct {
+	mu        sync.Mutex
+	base      *xlog.Logger                   // 调整前的默认 logger，未调整时为 nil
+	loggers   map[zapcore.Level]*xlog.Logger // 按级别缓存，避免每次调整都打开新的 writer
+	current   zapcore.Level
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// SetLevel 临时调整全局日志级别，ttl 为 0 时使用 DefaultTTL
+func SetLevel(lvText string, ttl time.Duration) (LevelInfo, error) {
+	var lv zapcore.Level
+	if err := lv.UnmarshalText([]byte(strings.ToLower(lvText))); err != nil {
+		return LevelInfo{}, err
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	level.mu.Lock()
+	defer level.mu.Unlock()
+	if level.timer != nil {
+		level.timer.Stop()
+	}
+	if level.base == nil {
+		level.base = xlog.DefaultLogger()
+	}
+	xlog.Warn("Log Level Changed",
+		xlog.FieldComponentName("XLogger"),
+		xlog.FieldValue(lv.String()),
+		xlog.Duration("ttl", ttl),
+	)
+	xlog.SetDefaultLogger(loggerAt(lv))
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		level.mu.Lock()
+		defer level.mu.Unlock()
+		if level.timer == timer {
+			resetLocked()
+		}
+	})
+	level.current, level.expiresAt, level.timer = lv, time.Now().Add(ttl), timer
+	return LevelInfo{Level: lv.String(), ExpiresAt: &level.expiresAt}, nil
+}
+
+// ResetLevel 立即恢复配置中的全局日志级别
+func ResetLevel() {
+	level.mu.Lock()
+	defer level.mu.Unlock()
+	resetLocked()
+}
+
+// Level 当前生效的全局日志级别
+func Level() LevelInfo {
+	level.mu.Lock()
+	defer level.mu.Unlock()
+	if level.base == nil {
+		return LevelInfo{Level: configLevel()}
+	}
+	expiresAt := level.expiresAt
+	return LevelInfo{Level: level.current.String(), ExpiresAt: &expiresAt}
+}
+
+func resetLocked() {
+	if level.base == nil {
+		return
+	}
+	level.timer.Stop()
+	xlog.SetDefaultLogger(level.base)
+	level.base, level.timer = nil, nil
+	xlog.Warn("Log Level Restored", xlog.FieldComponentName("XLogger"))
+}
+
+// loggerAt 与默认 logger 使用相同的配置，不监听配置中的 level，避免临时调整期间被配置覆盖
+func loggerAt(lv zapcore.Level) *xlog.Logger {
+	if l, ok := level.loggers[lv]; ok {
+		return l
+	}
+	config := xlog.StdConfig()
+	config.ConfigKey = ""
+	l := config.Build(xlog.WithLevel(lv.String()))
+	if level.loggers == nil {
+		level.loggers = make(map[zapcore.Level]*xlog.Logger)
+	}
+	level.loggers[lv] = l
+	return l
+}
+
+// configLevel 与 xlog.StdConfig 一致，未配置时为 info
+func configLevel() string {
+	if lv := xcfg.GetString("xlog.level"); lv != "" {
+		return strings.ToLower(lv)
+	}
+	return zapcore.InfoLevel.String()
+}
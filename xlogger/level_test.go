@@ -0,0 +1,101 @@
+package xlogger
+
+import (
+	"encoding/json"
+	"github.com/coder2z/g-saber/xlog"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelExpire(t *testing.T) {
+	base := xlog.DefaultLogger()
+	if _, err := SetLevel("verbose", time.Minute); err == nil {
+		t.Fatal("invalid level accepted")
+	}
+
+	if _, err := SetLevel("debug", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	debug := xlog.DefaultLogger()
+	if _, err := SetLevel("DEBUG", 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if xlog.DefaultLogger() != debug || xlog.DefaultLogger() == base {
+		t.Fatal("debug logger should be cached and replace the default logger")
+	}
+	if info := Level(); info.Level != "debug" || info.ExpiresAt == nil {
+		t.Fatalf("level = %+v", info)
+	}
+
+	xlog.Debug("runtime level debug")
+	b, err := ioutil.ReadFile(xlog.StdConfig().Filename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "runtime level debug") {
+		t.Fatalf("log = %s", b)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if xlog.DefaultLogger() != base {
+		t.Fatal("default logger not restored after ttl")
+	}
+	if info := Level(); info.Level != "info" || info.ExpiresAt != nil {
+		t.Fatalf("level = %+v", info)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	defer ResetPayload()
+	if _, ok := Payload(); ok {
+		t.Fatal("payload should be unset")
+	}
+	SetPayload(false, time.Minute)
+	if enabled, ok := Payload(); enabled || !ok {
+		t.Fatalf("payload = %v, %v", enabled, ok)
+	}
+	SetPayload(true, 50*time.Millisecond)
+	if enabled, ok := Payload(); !enabled || !ok {
+		t.Fatalf("payload = %v, %v", enabled, ok)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if _, ok := Payload(); ok {
+		t.Fatal("payload should expire")
+	}
+}
+
+func TestLevelHttp(t *testing.T) {
+	defer ResetLevel()
+
+	w := httptest.NewRecorder()
+	LevelHttp(w, httptest.NewRequest(http.MethodPost, "/debug/log/level?level=warn&ttl=1m", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", w.Code, w.Body)
+	}
+	var info LevelInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Level != "warn" || info.ExpiresAt == nil {
+		t.Fatalf("level = %+v", info)
+	}
+
+	for _, target := range []string{"/debug/log/level?level=verbose", "/debug/log/level?component=XGrpc&level=debug", "/debug/log/level?level=debug&ttl=1x"} {
+		w = httptest.NewRecorder()
+		LevelHttp(w, httptest.NewRequest(http.MethodPost, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: code = %d", target, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	LevelHttp(w, httptest.NewRequest(http.MethodDelete, "/debug/log/level", nil))
+	info = LevelInfo{}
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil || info.Level != "info" || info.ExpiresAt != nil {
+		t.Fatalf("level = %+v, err = %v", info, err)
+	}
+}
@@ -0,0 +1,82 @@
+package xlogger
+
+import (
+	"fmt"
+	"github.com/coder2z/g-saber/xlog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	payloadUnset int32 = iota
+	payloadOn
+	payloadOff
+)
+
+// payload gRPC logger 拦截器记录请求和响应内容的运行时开关
+var payload struct {
+	mu    sync.Mutex
+	state int32
+	timer *time.Timer
+}
+
+// SetPayload 临时开启或关闭 gRPC logger 拦截器记录请求和响应内容，ttl 为 0 时使用 DefaultTTL，返回到期时间
+func SetPayload(enabled bool, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	payload.mu.Lock()
+	defer payload.mu.Unlock()
+	if payload.timer != nil {
+		payload.timer.Stop()
+	}
+	state := payloadOff
+	if enabled {
+		state = payloadOn
+	}
+	atomic.StoreInt32(&payload.state, state)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		payload.mu.Lock()
+		defer payload.mu.Unlock()
+		if payload.timer == timer {
+			resetPayloadLocked()
+		}
+	})
+	payload.timer = timer
+
+	xlog.Warn("Log Payload Changed",
+		xlog.FieldComponentName("XLogger"),
+		xlog.FieldValue(fmt.Sprint(enabled)),
+		xlog.Duration("ttl", ttl),
+	)
+	return time.Now().Add(ttl)
+}
+
+// ResetPayload 立即恢复为拦截器自身的配置
+func ResetPayload() {
+	payload.mu.Lock()
+	defer payload.mu.Unlock()
+	resetPayloadLocked()
+}
+
+func resetPayloadLocked() {
+	if payload.timer != nil {
+		payload.timer.Stop()
+		payload.timer = nil
+	}
+	atomic.StoreInt32(&payload.state, payloadUnset)
+}
+
+// Payload 运行时的 payload 开关，ok 为 false 时使用拦截器自身的配置
+func Payload() (enabled bool, ok bool) {
+	switch atomic.LoadInt32(&payload.state) {
+	case payloadOn:
+		return true, true
+	case payloadOff:
+		return false, true
+	}
+	return false, false
+}
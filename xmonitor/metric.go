@@ -41,9 +41,30 @@ var (
 	// ServerHandleHistogram ...
 	ServerHandleHistogram = NewHistogramVec("server_handle_seconds", []string{"type", "name", "method", "peer"})
 
+	// ServerRateLimitCounter ...	指标: 服务类型，服务名称，调用方法
+	ServerRateLimitCounter = NewCounterVec("server_rate_limit_total", []string{"type", "name", "method"})
+
+	// SheddingGauge ...	指标: 降载器名称，状态项(cpu, in_flight, max_in_flight, min_rt, dropping, dropped)
+	SheddingGauge = NewGaugeVec("server_shedding", []string{"name", "stat"})
+
+	// HealthProbeGauge ...	指标: 探针名称; 值: 1 正常，0 异常
+	HealthProbeGauge = NewGaugeVec("health_probe_up", []string{"name"})
+
 	// ClientHandleCounter ... 	指标: 客户端类型，客户端名称，调用方法，目标，返回的状态码
 	ClientHandleCounter = NewCounterVec("client_handle_total", []string{"type", "name", "method", "peer", "code"})
 
+	// ClientRetryCounter ...	指标: 客户端类型，客户端名称，调用方法，目标，触发重试的状态码
+	ClientRetryCounter = NewCounterVec("client_retry_total", []string{"type", "name", "method", "peer", "code"})
+
+	// ClientHedgeCounter ...	指标: 客户端类型，客户端名称，调用方法，目标，事件(sent, won, budget exhausted)
+	ClientHedgeCounter = NewCounterVec("client_hedge_total", []string{"type", "name", "method", "peer", "event"})
+
+	// ClientBreakerStateGauge ...	指标: 目标，调用方法; 值: 0 关闭，1 打开，2 半开
+	ClientBreakerStateGauge = NewGaugeVec("client_breaker_state", []string{"peer", "method"})
+
+	// ClientBreakerTransitionCounter ...	指标: 目标，调用方法，原状态，新状态
+	ClientBreakerTransitionCounter = NewCounterVec("client_breaker_transition_total", []string{"peer", "method", "from", "to"})
+
 	// ClientHandleHistogram ...
 	ClientHandleHistogram = NewHistogramVec("client_handle_seconds", []string{"type", "name", "method", "peer"})
 
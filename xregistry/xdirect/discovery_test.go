@@ -7,6 +7,7 @@ import (
 	"github.com/coder2z/g-saber/xstring"
 	"github.com/coder2z/g-server/xgrpc"
 	"github.com/coder2z/g-server/xgrpc/balancer/least_connection"
+	"github.com/coder2z/g-server/xgrpc/breaker"
 	clientinterceptors "github.com/coder2z/g-server/xgrpc/client"
 	serverinterceptors "github.com/coder2z/g-server/xgrpc/server"
 	"google.golang.org/grpc"
@@ -30,14 +31,7 @@ func TestClientDirect(t *testing.T) {
 		),
 		xgrpc.WithUnaryClientInterceptors(
 			clientinterceptors.XAidUnaryClientInterceptor(),
-			clientinterceptors.HystrixUnaryClientIntercept(1000,
-				30,
-				20,
-				30,
-				20,
-				func(ctx context.Context, err error) error {
-					return err
-				}),
+			clientinterceptors.BreakerUnaryClientInterceptor(breaker.NewGroup(breaker.DefaultGroupConfig())),
 			clientinterceptors.XTimeoutUnaryClientInterceptor(time.Minute, time.Second),
 			clientinterceptors.XLoggerUnaryClientInterceptor("servername"),
 			clientinterceptors.PrometheusUnaryClientInterceptor("servername"),
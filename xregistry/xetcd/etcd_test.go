@@ -1,12 +1,12 @@
 package xetcd
 
 import (
-	"context"
 	"fmt"
 	"github.com/coder2z/g-saber/xtime"
 	"github.com/coder2z/g-server/xgrpc"
 	xbalancer "github.com/coder2z/g-server/xgrpc/balancer"
 	"github.com/coder2z/g-server/xgrpc/balancer/least_connection"
+	"github.com/coder2z/g-server/xgrpc/breaker"
 	clientinterceptors "github.com/coder2z/g-server/xgrpc/client"
 	serverinterceptors "github.com/coder2z/g-server/xgrpc/server"
 	"github.com/coder2z/g-server/xregistry"
@@ -106,14 +106,7 @@ func TestEtcdDiscovery(t *testing.T) {
 		),
 		xgrpc.WithUnaryClientInterceptors(
 			clientinterceptors.XAidUnaryClientInterceptor(),
-			clientinterceptors.HystrixUnaryClientIntercept(1000,
-				30,
-				20,
-				30,
-				20,
-				func(ctx context.Context, err error) error {
-					return err
-				}),
+			clientinterceptors.BreakerUnaryClientInterceptor(breaker.NewGroup(breaker.DefaultGroupConfig())),
 			clientinterceptors.XTimeoutUnaryClientInterceptor(time.Minute, time.Second),
 			clientinterceptors.XLoggerUnaryClientInterceptor("servername"),
 			clientinterceptors.PrometheusUnaryClientInterceptor("servername"),
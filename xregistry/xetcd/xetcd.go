@@ -2,10 +2,12 @@ package xetcd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/coder2z/g-saber/xlog"
 	"github.com/coder2z/g-saber/xstring"
 	"github.com/coder2z/g-server/xapp"
+	"github.com/coder2z/g-server/xhealth"
 	"github.com/coder2z/g-server/xregistry"
 	"go.etcd.io/etcd/clientv3"
 	"sync"
@@ -85,16 +87,28 @@ func (r *etcdReg) Register(ops ...xregistry.Option) {
 		xlog.FieldValueAny(r.options),
 	)
 
+	r.Add(1)
 	go func() {
-		r.Add(1)
 		defer r.Done()
-		var err error
-		err = r.register() // 先注册一次
+		err := errors.New("dependencies not ready")
+		// 探针注册后状态为 UNKNOWN，先检查一次，依赖已就绪时不必等到下一个周期
+		xhealth.Check()
+		if xhealth.Ready().Up() { // 依赖就绪后才注册，否则等下一次检查
+			err = r.register()
+		}
 		ticker := time.NewTicker(r.options.RegisterInterval)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
+				if !xhealth.Ready().Up() { // 依赖未就绪时注销，恢复后重新注册
+					if r.isOk {
+						r.unregister()
+						r.isOk = false
+					}
+					err = errors.New("dependencies not ready")
+					continue
+				}
 				if err == nil { // 注册成功则续租
 					err = r.keepAliveOnce()
 				}